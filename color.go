@@ -0,0 +1,178 @@
+package game
+
+import (
+	"fmt"
+	"os"
+	"strings"
+)
+
+// ColorProfile the colors a terminal can show
+type ColorProfile uint8
+
+const (
+	ColorsNone ColorProfile = iota // no color, only the text attributes
+	Colors8                        // the 8 colors and their bright versions
+	Colors256
+	ColorsRGB // true color
+)
+
+// ColorsEnv the environment variable overriding the detected profile: none, 8, 256 or truecolor
+const ColorsEnv = "GAME_COLORS"
+
+// String profile name, as ParseColorProfile accepts
+func (p ColorProfile) String() string {
+	switch p {
+	case ColorsNone:
+		return "none"
+	case Colors8:
+		return "8"
+	case Colors256:
+		return "256"
+	case ColorsRGB:
+		return "truecolor"
+	}
+	return ""
+}
+
+// ParseColorProfile parse a profile name: none, 8, 16, 256, truecolor or 24bit
+func ParseColorProfile(s string) (ColorProfile, error) {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "none", "0", "no":
+		return ColorsNone, nil
+	case "8", "16":
+		return Colors8, nil
+	case "256":
+		return Colors256, nil
+	case "truecolor", "24bit", "rgb":
+		return ColorsRGB, nil
+	}
+	return ColorsNone, fmt.Errorf("unknown color profile %q", s)
+}
+
+// DetectColorProfile the profile of the terminal from the environment:
+// GAME_COLORS overrides, NO_COLOR turns colors off, then COLORTERM and TERM tell
+func DetectColorProfile() ColorProfile {
+	return detectColorProfile(os.Getenv)
+}
+
+func detectColorProfile(getenv func(string) string) ColorProfile {
+	if p, err := ParseColorProfile(getenv(ColorsEnv)); err == nil {
+		return p
+	}
+	if getenv("NO_COLOR") != "" { // https://no-color.org
+		return ColorsNone
+	}
+
+	switch strings.ToLower(getenv("COLORTERM")) {
+	case "truecolor", "24bit":
+		return ColorsRGB
+	}
+
+	term := strings.ToLower(getenv("TERM"))
+	switch {
+	case term == "" || term == "dumb":
+		return ColorsNone
+	case strings.Contains(term, "truecolor") || strings.Contains(term, "direct"):
+		return ColorsRGB
+	case strings.Contains(term, "256color"):
+		return Colors256
+	}
+	return Colors8
+}
+
+// Convert the nearest color the profile `p` can show
+func (c Color) Convert(p ColorProfile) Color {
+	switch {
+	case c.Mode == ColorModeDefault || p == ColorsRGB:
+		return c
+	case p == ColorsNone:
+		return ColorDefault
+	case c.Mode == ColorModeRGB && p == Colors256:
+		return Color256(nearest256(c.RGB()))
+	case c.Mode == ColorModeRGB, c.Mode == ColorMode256 && p == Colors8:
+		return Color{Mode: ColorMode8, Value: uint32(nearest(c.rgb(), palette[:16]))}
+	}
+	return c
+}
+
+// Convert the style with the colors the profile `p` can show
+func (s Style) Convert(p ColorProfile) Style {
+	s.Fg, s.Bg = s.Fg.Convert(p), s.Bg.Convert(p)
+	return s
+}
+
+// rgb the red, green and blue of any color, the xterm defaults for the indexed ones
+func (c Color) rgb() RGB {
+	switch c.Mode {
+	case ColorModeRGB:
+		return c.RGB()
+	case ColorMode8, ColorMode256:
+		return palette[c.Value&0xff]
+	}
+	return RGB{}
+}
+
+// palette the xterm 256 colors
+var palette = func() (p [256]RGB) {
+	copy(p[:], []RGB{
+		{0x00, 0x00, 0x00}, {0xcd, 0x00, 0x00}, {0x00, 0xcd, 0x00}, {0xcd, 0xcd, 0x00},
+		{0x00, 0x00, 0xee}, {0xcd, 0x00, 0xcd}, {0x00, 0xcd, 0xcd}, {0xe5, 0xe5, 0xe5},
+		{0x7f, 0x7f, 0x7f}, {0xff, 0x00, 0x00}, {0x00, 0xff, 0x00}, {0xff, 0xff, 0x00},
+		{0x5c, 0x5c, 0xff}, {0xff, 0x00, 0xff}, {0x00, 0xff, 0xff}, {0xff, 0xff, 0xff},
+	})
+	for i := 0; i < 216; i++ { // 6x6x6 cube
+		p[16+i] = RGB{cubeLevels[i/36], cubeLevels[i/6%6], cubeLevels[i%6]}
+	}
+	for i := 0; i < 24; i++ { // grays
+		v := uint8(8 + 10*i)
+		p[232+i] = RGB{v, v, v}
+	}
+	return
+}()
+
+var cubeLevels = [6]uint8{0, 95, 135, 175, 215, 255}
+
+// nearest256 the nearest of the cube and the grays, the 16 system colors are left out
+// as terminals often change them
+func nearest256(c RGB) uint8 {
+	cube := 16 + 36*nearestLevel(c[0]) + 6*nearestLevel(c[1]) + nearestLevel(c[2])
+	gray := 232 + nearest(c, palette[232:])
+	if distance(c, palette[gray]) < distance(c, palette[cube]) {
+		return uint8(gray)
+	}
+	return uint8(cube)
+}
+
+func nearestLevel(v uint8) int {
+	best := 0
+	for i, l := range cubeLevels {
+		if absDiff(v, l) < absDiff(v, cubeLevels[best]) {
+			best = i
+		}
+	}
+	return best
+}
+
+// nearest the index of the color in `colors` nearest to `c`
+func nearest(c RGB, colors []RGB) int {
+	best := 0
+	for i, o := range colors {
+		if distance(c, o) < distance(c, colors[best]) {
+			best = i
+		}
+	}
+	return best
+}
+
+// distance square of the weighted euclidean distance, close enough to what the eyes see
+func distance(a, b RGB) int {
+	dr, dg, db := absDiff(a[0], b[0]), absDiff(a[1], b[1]), absDiff(a[2], b[2])
+	return 2*dr*dr + 4*dg*dg + 3*db*db
+}
+
+func absDiff(a, b uint8) int {
+	if a > b {
+		return int(a - b)
+	}
+	return int(b - a)
+}
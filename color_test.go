@@ -0,0 +1,83 @@
+package game
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestDetectColorProfile(t *testing.T) {
+	tests := []struct {
+		env  map[string]string
+		want ColorProfile
+	}{
+		{map[string]string{}, ColorsNone},
+		{map[string]string{"TERM": "dumb"}, ColorsNone},
+		{map[string]string{"TERM": "linux"}, Colors8},
+		{map[string]string{"TERM": "xterm-256color"}, Colors256},
+		{map[string]string{"TERM": "xterm-256color", "COLORTERM": "truecolor"}, ColorsRGB},
+		{map[string]string{"TERM": "xterm-direct"}, ColorsRGB},
+		{map[string]string{"TERM": "xterm-256color", "NO_COLOR": "1"}, ColorsNone},
+		{map[string]string{"TERM": "xterm-256color", "NO_COLOR": ""}, Colors256},
+		{map[string]string{"TERM": "dumb", "NO_COLOR": "1", ColorsEnv: "256"}, Colors256},
+		{map[string]string{"TERM": "linux", ColorsEnv: "bad"}, Colors8},
+	}
+
+	for _, tt := range tests {
+		getenv := func(k string) string { return tt.env[k] }
+		if got := detectColorProfile(getenv); got != tt.want {
+			t.Errorf("%v: profile %v, want %v", tt.env, got, tt.want)
+		}
+	}
+}
+
+func TestColorConvert(t *testing.T) {
+	orange := RGB{0xff, 0x87, 0x00}.Color()
+	tests := []struct {
+		c    Color
+		p    ColorProfile
+		want Color
+	}{
+		{orange, ColorsRGB, orange},
+		{orange, Colors256, Color256(208)},
+		{orange, Colors8, ColorYellow.Color()},
+		{orange, ColorsNone, ColorDefault},
+		{RGB{0x80, 0x80, 0x80}.Color(), Colors256, Color256(244)},
+		{RGB{0x10, 0x10, 0xe0}.Color(), Colors8, ColorBlue.Color()},
+		{Color256(196), Colors8, ColorRed.Bright()},
+		{Color256(196), Colors256, Color256(196)},
+		{ColorGreen.Color(), Colors256, ColorGreen.Color()},
+		{ColorDefault, Colors8, ColorDefault},
+	}
+
+	for _, tt := range tests {
+		if got := tt.c.Convert(tt.p); got != tt.want {
+			t.Errorf("%v to %v = %v, want %v", tt.c, tt.p, got, tt.want)
+		}
+	}
+}
+
+func TestOutputColorProfile(t *testing.T) {
+	var buf bytes.Buffer
+	o := NewOutput(&buf)
+	o.SetColorProfile(Colors256)
+
+	o.DrawColorRGB(Foreground, RGB{0xff, 0x87, 0x00}, "x")
+	o.DrawStyle("y", Style{Bg: RGB{0xff, 0x87, 0x00}.Color(), Attr: AttrBold})
+	if got, want := buf.String(), "\x1b[38;5;208mx\x1b[m\x1b[0;1;48;5;208my\x1b[m"; got != want {
+		t.Errorf("256 colors = %q, want %q", got, want)
+	}
+
+	buf.Reset()
+	o.SetColorProfile(ColorsNone)
+	o.DrawColor8(BrightForeground, ColorRed, "x")
+	if got, want := buf.String(), "x\x1b[m"; got != want {
+		t.Errorf("no colors = %q, want %q", got, want)
+	}
+
+	s := NewScreen(1, 3)
+	s.Set(Point{}, '@', Style{Fg: Color256(196)})
+	s.render(Colors8)
+	if got, want := s.buf.String(), SgrNormal+ClearAll+cursorPos(1, 1)+Sgr("0;91")+"@"+SgrNormal; got != want {
+		t.Errorf("screen with 8 colors = %q, want %q", got, want)
+	}
+}
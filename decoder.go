@@ -0,0 +1,294 @@
+package game
+
+import (
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// csi `~` sequences, ESC [ n ~
+var tildeKeys = map[int]int{
+	1:  SysHome,
+	2:  SysInsert,
+	3:  SysDelete,
+	4:  SysEnd,
+	5:  SysPgUp,
+	6:  SysPgDn,
+	7:  SysHome,
+	8:  SysEnd,
+	11: SysF1,
+	12: SysF2,
+	13: SysF3,
+	14: SysF4,
+	15: SysF5,
+	17: SysF6,
+	18: SysF7,
+	19: SysF8,
+	20: SysF9,
+	21: SysF10,
+	23: SysF11,
+	24: SysF12,
+}
+
+// csi and ss3 sequences by their final byte, ESC [ 1 ; m X or ESC O X
+var finalKeys = map[byte]int{
+	'A': SysUp,
+	'B': SysDown,
+	'C': SysRight,
+	'D': SysLeft,
+	'H': SysHome,
+	'F': SysEnd,
+	'P': SysF1,
+	'Q': SysF2,
+	'R': SysF3,
+	'S': SysF4,
+}
+
+// Decoder decode raw terminal input into keys.
+// An incomplete sequence at the end of the input is kept until more input arrives,
+// or until Flush is called because nothing arrived in time, which tells a standalone Esc
+// from the start of an escape sequence.
+type Decoder struct {
+	pending []byte
+}
+
+// Feed decode `p` with the pending input, and return the complete keys
+func (d *Decoder) Feed(p []byte) []Key {
+	d.pending = append(d.pending, p...)
+	return d.decode(false)
+}
+
+// Pending whether an incomplete sequence is waiting for more input
+func (d *Decoder) Pending() bool {
+	return len(d.pending) > 0
+}
+
+// Flush decode the pending input as it is, called when no more input arrives in time
+func (d *Decoder) Flush() []Key {
+	return d.decode(true)
+}
+
+func (d *Decoder) decode(flush bool) (keys []Key) {
+	for len(d.pending) > 0 {
+		k, n := decodeKey(d.pending, flush)
+		if n == 0 { // wait for more
+			break
+		}
+		keys = append(keys, k)
+		d.pending = d.pending[n:]
+	}
+
+	if len(d.pending) == 0 {
+		d.pending = nil
+	}
+	return
+}
+
+// decodeKey decode the first key of `b`, return the bytes used, 0 if `b` is incomplete
+func decodeKey(b []byte, flush bool) (Key, int) {
+	if b[0] >= utf8.RuneSelf {
+		return decodeRune(b, flush)
+	} else if b[0] != SysEsc {
+		return decodeByte(b[0]), 1
+	}
+
+	if len(b) == 1 {
+		if flush {
+			return Key{Code: SysEsc}, 1
+		}
+		return Key{}, 0
+	}
+
+	switch b[1] {
+	case '[':
+		if k, n := decodeCSI(b); n > 0 || !flush {
+			return k, n
+		}
+	case 'O':
+		if k, n := decodeSS3(b); n > 0 || !flush {
+			return k, n
+		}
+	case SysEsc:
+		return Key{Code: SysEsc}, 1
+	default: // alt + key
+		k, n := decodeKey(b[1:], flush)
+		if n > 0 {
+			k.Mod |= ModAlt
+			n++
+		}
+		return k, n
+	}
+
+	// incomplete sequence on flush, alt + `[` or `O`
+	k := decodeByte(b[1])
+	k.Mod |= ModAlt
+	return k, 2
+}
+
+// decodeByte decode a single ascii byte
+func decodeByte(c byte) Key {
+	switch {
+	case c == SysTab, c == SysEnter, c == SysEsc, c == SysBackspace:
+		return Key{Code: int(c)}
+	case c == '\n':
+		return Key{Code: SysEnter}
+	case c < ' ':
+		return Key{Code: int(c), Mod: ModCtrl}
+	default:
+		return Key{Code: int(c), Rune: rune(c)}
+	}
+}
+
+// decodeRune decode a multi-byte utf-8 character, which may be split across reads
+func decodeRune(b []byte, flush bool) (Key, int) {
+	if !utf8.FullRune(b) {
+		if flush {
+			return Key{Code: SysParse, Raw: string(b)}, len(b)
+		}
+		return Key{}, 0
+	}
+
+	r, n := utf8.DecodeRune(b)
+	if r == utf8.RuneError {
+		return Key{Code: SysParse, Raw: string(b[:n])}, n
+	}
+	return Key{Code: SysRune, Rune: r}, n
+}
+
+// decodeCSI decode ESC [ params intermediates final
+func decodeCSI(b []byte) (Key, int) {
+	// linux console F1-F5, ESC [ [ A-E
+	if len(b) > 2 && b[2] == '[' {
+		if len(b) < 4 {
+			return Key{}, 0
+		}
+		if b[3] >= 'A' && b[3] <= 'E' {
+			return Key{Code: SysF1 + int(b[3]-'A')}, 4
+		}
+		return Key{Code: SysParse, Raw: string(b[:4])}, 4
+	}
+
+	// x10 mouse, ESC [ M b x y
+	if len(b) > 2 && b[2] == 'M' {
+		if len(b) < 6 {
+			return Key{}, 0
+		}
+		e := decodeMouse(int(b[3])-32, int(b[4])-32, int(b[5])-32, false)
+		return Key{Code: SysMouse, Mouse: e}, 6
+	}
+
+	i := 2
+	for i < len(b) && b[i] >= 0x30 && b[i] <= 0x3f { // params
+		i++
+	}
+	for i < len(b) && b[i] >= 0x20 && b[i] <= 0x2f { // intermediates
+		i++
+	}
+	if i >= len(b) {
+		return Key{}, 0
+	}
+
+	n := i + 1
+	if b[i] < 0x40 || b[i] > 0x7e { // not a final byte
+		return Key{Code: SysParse, Raw: string(b[:n])}, n
+	}
+
+	params, final := string(b[2:i]), b[i]
+	if strings.HasPrefix(params, "<") && (final == 'M' || final == 'm') { // sgr mouse
+		if args := splitParams(params[1:]); len(args) == 3 {
+			e := decodeMouse(args[0], args[1], args[2], final == 'm')
+			return Key{Code: SysMouse, Mouse: e}, n
+		}
+		return Key{Code: SysParse, Raw: string(b[:n])}, n
+	}
+
+	code, mod, ok := csiKey(params, final)
+	if !ok {
+		return Key{Code: SysParse, Raw: string(b[:n])}, n
+	}
+	return Key{Code: code, Mod: mod}, n
+}
+
+func csiKey(params string, final byte) (code int, mod Mod, ok bool) {
+	args := splitParams(params)
+	if len(args) > 1 {
+		mod = modifier(args[1])
+	}
+
+	switch final {
+	case '~':
+		if len(args) == 0 {
+			return
+		}
+		code, ok = tildeKeys[args[0]]
+	case 'Z': // back tab
+		code, mod, ok = SysTab, mod|ModShift, true
+	case 'I', 'O': // focus reports
+		if params == "" {
+			code, ok = SysFocusIn, true
+			if final == 'O' {
+				code = SysFocusOut
+			}
+		}
+	default:
+		code, ok = finalKeys[final]
+	}
+	return
+}
+
+// decodeSS3 decode ESC O [modifier] final
+func decodeSS3(b []byte) (Key, int) {
+	i := 2
+	for i < len(b) && b[i] >= '0' && b[i] <= '9' {
+		i++
+	}
+	if i >= len(b) {
+		return Key{}, 0
+	}
+
+	n := i + 1
+	var mod Mod
+	if i > 2 {
+		m, _ := strconv.Atoi(string(b[2:i]))
+		mod = modifier(m)
+	}
+
+	if b[i] == 'M' { // keypad enter
+		return Key{Code: SysEnter, Mod: mod}, n
+	}
+	if code, ok := finalKeys[b[i]]; ok {
+		return Key{Code: code, Mod: mod}, n
+	}
+	return Key{Code: SysParse, Raw: string(b[:n])}, n
+}
+
+// splitParams split `1;5` into [1, 5], empty params are 1 as the default
+func splitParams(params string) []int {
+	if params == "" {
+		return nil
+	}
+
+	fields := strings.Split(params, ";")
+	args := make([]int, len(fields))
+	for i, f := range fields {
+		if n, err := strconv.Atoi(f); err == nil {
+			args[i] = n
+		} else {
+			args[i] = 1
+		}
+	}
+	return args
+}
+
+// modifier the xterm modifier parameter: 1 + (shift:1 | alt:2 | ctrl:4 | meta:8)
+func modifier(m int) Mod {
+	if m <= 1 {
+		return 0
+	}
+	m--
+	mod := Mod(m) & (ModShift | ModAlt | ModCtrl)
+	if m&8 != 0 { // meta
+		mod |= ModAlt
+	}
+	return mod
+}
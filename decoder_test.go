@@ -0,0 +1,106 @@
+package game
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestDecoder(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+		want []Key
+	}{
+		{"char", "a", []Key{{Code: 'a', Rune: 'a'}}},
+		{"ctrl", "\x03", []Key{{Code: 3, Mod: ModCtrl}}},
+		{"enter", "\r", []Key{{Code: SysEnter}}},
+		{"arrow", "\x1b[A", []Key{{Code: SysUp}}},
+		{"ss3 arrow", "\x1bOD", []Key{{Code: SysLeft}}},
+		{"ctrl arrow", "\x1b[1;5C", []Key{{Code: SysRight, Mod: ModCtrl}}},
+		{"shift alt arrow", "\x1b[1;4B", []Key{{Code: SysDown, Mod: ModShift | ModAlt}}},
+		{"home end", "\x1b[H\x1b[4~", []Key{{Code: SysHome}, {Code: SysEnd}}},
+		{"page", "\x1b[5~\x1b[6;2~", []Key{{Code: SysPgUp}, {Code: SysPgDn, Mod: ModShift}}},
+		{"insert delete", "\x1b[2~\x1b[3~", []Key{{Code: SysInsert}, {Code: SysDelete}}},
+		{"f1 ss3", "\x1bOP", []Key{{Code: SysF1}}},
+		{"f5", "\x1b[15~", []Key{{Code: SysF5}}},
+		{"f12", "\x1b[24~", []Key{{Code: SysF12}}},
+		{"ctrl f3", "\x1b[1;5R", []Key{{Code: SysF3, Mod: ModCtrl}}},
+		{"linux console f2", "\x1b[[B", []Key{{Code: SysF2}}},
+		{"back tab", "\x1b[Z", []Key{{Code: SysTab, Mod: ModShift}}},
+		{"focus", "\x1b[O\x1b[I", []Key{{Code: SysFocusOut}, {Code: SysFocusIn}}},
+		{"alt key", "\x1bx", []Key{{Code: 'x', Rune: 'x', Mod: ModAlt}}},
+		{"double esc", "\x1b\x1b[A", []Key{{Code: SysEsc}, {Code: SysUp}}},
+		{"several", "w\x1b[Aq", []Key{{Code: 'w', Rune: 'w'}, {Code: SysUp}, {Code: 'q', Rune: 'q'}}},
+		{"unknown", "\x1b[99~", []Key{{Code: SysParse, Raw: "\x1b[99~"}}},
+		{"chinese", "贪吃蛇", []Key{{Code: SysRune, Rune: '贪'}, {Code: SysRune, Rune: '吃'}, {Code: SysRune, Rune: '蛇'}}},
+		{"accent", "é", []Key{{Code: SysRune, Rune: 'é'}}},
+		{"emoji", "a🐍", []Key{{Code: 'a', Rune: 'a'}, {Code: SysRune, Rune: '🐍'}}},
+		{"alt rune", "\x1bé", []Key{{Code: SysRune, Rune: 'é', Mod: ModAlt}}},
+		{"invalid", "\xffa", []Key{{Code: SysParse, Raw: "\xff"}, {Code: 'a', Rune: 'a'}}},
+		{"mouse press", "\x1b[<0;10;5M", []Key{{Code: SysMouse, Mouse: MouseEvent{Point: Point{X: 4, Y: 9}, Button: MouseLeft}}}},
+		{"mouse release", "\x1b[<2;1;1m", []Key{{Code: SysMouse, Mouse: MouseEvent{Button: MouseRight, Action: MouseRelease}}}},
+		{"mouse drag", "\x1b[<48;3;2M", []Key{{Code: SysMouse, Mouse: MouseEvent{Point: Point{X: 1, Y: 2}, Button: MouseLeft, Action: MouseDrag, Mod: ModCtrl}}}},
+		{"mouse move", "\x1b[<35;3;2M", []Key{{Code: SysMouse, Mouse: MouseEvent{Point: Point{X: 1, Y: 2}, Action: MouseMove}}}},
+		{"mouse wheel", "\x1b[<65;7;8M", []Key{{Code: SysMouse, Mouse: MouseEvent{Point: Point{X: 7, Y: 6}, Button: MouseWheelDown}}}},
+		{"x10 mouse", "\x1b[M !\"", []Key{{Code: SysMouse, Mouse: MouseEvent{Button: MouseLeft, Point: Point{X: 1}}}}},
+		{"truncated", "\xe8\xb4", []Key{{Code: SysParse, Raw: "\xe8\xb4"}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var d Decoder
+			got := append(d.Feed([]byte(tt.in)), d.Flush()...)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("decode %q = %v, want %v", tt.in, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDecoderSplit(t *testing.T) {
+	var d Decoder
+
+	if keys := d.Feed([]byte("\x1b")); len(keys) != 0 || !d.Pending() {
+		t.Fatalf("lone ESC decoded before timeout: %v", keys)
+	}
+	if keys := d.Feed([]byte("[1;")); len(keys) != 0 {
+		t.Fatalf("partial sequence decoded: %v", keys)
+	}
+	if keys := d.Feed([]byte("5A")); !reflect.DeepEqual(keys, []Key{{Code: SysUp, Mod: ModCtrl}}) {
+		t.Errorf("split sequence = %v", keys)
+	}
+
+	d.Feed([]byte("\x1b"))
+	if keys := d.Flush(); !reflect.DeepEqual(keys, []Key{{Code: SysEsc}}) || d.Pending() {
+		t.Errorf("flushed ESC = %v", keys)
+	}
+}
+
+func TestDecoderSplitRune(t *testing.T) {
+	var d Decoder
+	b := []byte("蛇")
+
+	for i := 0; i < len(b)-1; i++ {
+		if keys := d.Feed(b[i : i+1]); len(keys) != 0 {
+			t.Fatalf("partial rune decoded: %v", keys)
+		}
+	}
+	if keys := d.Feed(b[len(b)-1:]); !reflect.DeepEqual(keys, []Key{{Code: SysRune, Rune: '蛇'}}) {
+		t.Errorf("split rune = %v", keys)
+	}
+}
+
+func TestKeyString(t *testing.T) {
+	tests := map[string]Key{
+		"a":            {Code: 'a', Rune: 'a'},
+		"Space":        {Code: ' ', Rune: ' '},
+		"Up":           {Code: SysUp},
+		"Ctrl+C":       {Code: 3, Mod: ModCtrl},
+		"Alt+Shift+F5": {Code: SysF5, Mod: ModAlt | ModShift},
+	}
+	for want, k := range tests {
+		if got := k.String(); got != want {
+			t.Errorf("%#v.String() = %q, want %q", k, got, want)
+		}
+	}
+}
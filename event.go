@@ -0,0 +1,44 @@
+package game
+
+import "time"
+
+// Event something for an EventGame to handle: Key, MouseEvent, FocusEvent, TickEvent, ResizeEvent or QuitEvent
+type Event interface {
+	isEvent()
+}
+
+// TickEvent the fixed-rate tick set by Runner.SetTick
+type TickEvent struct {
+	Time time.Time
+}
+
+// ResizeEvent the terminal window was resized
+type ResizeEvent struct {
+	Rows, Cols int
+}
+
+// FocusEvent the terminal gained or lost the focus, see Runner.EnableFocus
+type FocusEvent struct {
+	Focused bool
+}
+
+// QuitEvent the session is ending, e.g. on SIGINT/SIGTERM. It is the last event delivered.
+type QuitEvent struct{}
+
+func (Key) isEvent()         {}
+func (MouseEvent) isEvent()  {}
+func (FocusEvent) isEvent()  {}
+func (TickEvent) isEvent()   {}
+func (ResizeEvent) isEvent() {}
+func (QuitEvent) isEvent()   {}
+
+// Event the event of key `k`: the MouseEvent of a mouse report, the FocusEvent of a focus report, or the key itself
+func (k Key) Event() Event {
+	switch k.Code {
+	case SysMouse:
+		return k.Mouse
+	case SysFocusIn, SysFocusOut:
+		return FocusEvent{Focused: k.Code == SysFocusIn}
+	}
+	return k
+}
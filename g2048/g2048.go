@@ -4,9 +4,7 @@ import (
 	"fmt"
 	"github.com/zhaowk/game"
 	"math/rand"
-	"sort"
 	"strings"
-	"time"
 )
 
 const (
@@ -36,61 +34,108 @@ func (b block) String() string {
 }
 
 type g2048 struct {
-	size int
-	pane [][]block
-	msg  string
-	stop bool
+	size   int
+	pane   [][]block
+	msg    string
+	score  int // the sum of the merged tiles
+	runner *game.Runner
+	rand   *rand.Rand
+	theme  *game.Theme
+	keys   game.Keymap
 }
 
-func (g *g2048) Init(...interface{}) error {
-	rand.Seed(time.Now().UnixNano())
+func (g *g2048) Init(r *game.Runner, _ ...interface{}) (err error) {
+	if g.keys, err = r.Keymap(g2048Keys); err != nil {
+		return err
+	}
+	g.rand = r.Rand()
+	g.theme = r.Theme()
 	g.size = 4
+	g.runner = r
+	g.reset()
+
+	r.SetMinSize(g.layout().Size())
+	r.EnableMenu(g.reset)
+	return nil
+}
+
+// reset start a new game
+func (g *g2048) reset() {
 	g.pane = make([][]block, g.size)
 	for i := 0; i < g.size; i++ {
 		g.pane[i] = make([]block, g.size)
 	}
 
-	m, n := rand.Intn(g.size*g.size), rand.Intn(g.size*g.size)
+	m, n := g.rand.Intn(g.size*g.size), g.rand.Intn(g.size*g.size)
 	if m == n {
 		n = (n + g.size + 3) % (g.size * g.size)
 	}
 
 	g.pane[m/g.size][m%g.size] = block(2)
 	g.pane[n/g.size][n%g.size] = block(2)
-
-	g.draw()
-
-	return nil
+	g.msg = ""
+	g.score = 0
+	g.runner.SetScore(g.score)
 }
 
-func (g *g2048) Run(k int, _ string) {
-	switch k {
-	case 'w', 'W', game.SysUp:
+func (g *g2048) Update(e game.Event) {
+	k, ok := e.(game.Key)
+	if !ok {
+		return
+	}
+
+	switch g.keys.Action(k) {
+	case game.ActionUp:
 		g.moveUp()
-	case 's', 'S', game.SysDown:
+	case game.ActionDown:
 		g.moveDown()
-	case 'a', 'A', game.SysLeft:
+	case game.ActionLeft:
 		g.moveLeft()
-	case 'd', 'D', game.SysRight:
+	case game.ActionRight:
 		g.moveRight()
-	case 'q', 'Q':
+	case game.ActionQuit:
 		g.msg = "Quiting..."
-		g.stop = true
+		g.runner.Quit()
+		return
 	}
+	g.runner.SetScore(g.score)
+	g.check()
 }
 
-func (g *g2048) Next() bool {
-	// check stop
-	if g.stop {
-		return false
-	}
+func (g *g2048) Render(sc *game.Screen) {
+	game.DrawCentered(sc, g.layout())
+}
+
+// layout the board and the message below, the tips at right
+func (g *g2048) layout() game.Widget {
+	return game.HBox{Gap: 2, Children: []game.Widget{
+		game.VBox{Children: []game.Widget{
+			game.Canvas{Rows: g.size*4 + 1, Cols: g.size*7 + 1, Paint: g.drawBoard},
+			game.Label{Text: g.msg, Style: g.theme.Style(game.RoleTitle)},
+		}},
+		game.Box{Title: "Tips", Style: g.theme.Style(game.RoleBorder), Padding: 1, Child: game.KeyHelp{Bindings: g.keys}},
+	}}
+}
 
+// g2048Keys the default bindings, see ~/.config/g2048/keys.toml
+var g2048Keys = game.Keymap{
+	{Action: game.ActionQuit, Keys: []game.Key{game.KeyRune('q')}, Help: "exit"},
+	{Action: game.ActionMenu, Keys: []game.Key{game.KeyCode(game.SysEsc)}, Help: "menu"},
+	{Action: game.ActionLeft, Keys: []game.Key{game.KeyRune('a'), game.KeyCode(game.SysLeft)}, Help: "left"},
+	{Action: game.ActionRight, Keys: []game.Key{game.KeyRune('d'), game.KeyCode(game.SysRight)}, Help: "right"},
+	{Action: game.ActionUp, Keys: []game.Key{game.KeyRune('w'), game.KeyCode(game.SysUp)}, Help: "up"},
+	{Action: game.ActionDown, Keys: []game.Key{game.KeyRune('s'), game.KeyCode(game.SysDown)}, Help: "down"},
+}
+
+// check end the game on win or game over
+func (g *g2048) check() {
 	// check win
 	for i := 0; i < g.size; i++ {
 		for j := 0; j < g.size; j++ {
 			if g.pane[i][j] >= gGameMax {
 				g.msg = "Congratulations!"
-				return false
+				g.runner.GameOver(game.OutcomeWin)
+				return
 			}
 		}
 	}
@@ -99,44 +144,52 @@ func (g *g2048) Next() bool {
 	for i := 0; i < g.size; i++ {
 		for j := 0; j < g.size; j++ {
 			if g.pane[i][j] == 0 {
-				return true
+				return
 			}
 
 			if j > 0 && g.pane[i][j] == g.pane[i][j-1] {
-				return true
+				return
 			}
 
 			if i > 0 && g.pane[i][j] == g.pane[i-1][j] {
-				return true
+				return
 			}
 		}
 	}
 	// game over
 	g.msg = "Game over!"
-	return false
+	g.runner.GameOver(game.OutcomeLose)
 }
 
 func (g *g2048) Finish() {
-	game.DrawLine(g.msg)
 }
 
-func (g *g2048) draw() {
-	game.Clear()
-	game.DrawLineAt(game.Point{}, strings.Repeat(gWall, g.size*7+1))
+func (g *g2048) drawBoard(sc *game.Screen) {
+	width := g.size*7 + 1
+	wall := g.theme.Style(game.RoleWall)
+	sc.DrawString(game.Point{}, strings.Repeat(gWall, width), wall)
 
 	for i := 0; i < g.size; i++ {
 		for k := 0; k < 3; k++ {
-			game.Draw(gWall)
+			p := sc.DrawString(game.Point{X: i*4 + k + 1}, gWall, wall)
 			for j := 0; j < g.size; j++ {
-				game.Draw(fmt.Sprintf("%s%s", g.getContent(k, g.pane[i][j]), gSpace))
+				p = sc.DrawString(p, g.getContent(k, g.pane[i][j]), g.style(g.pane[i][j]))
+				p = sc.DrawString(p, gSpace, game.StyleDefault)
 			}
-			game.CursorBack(1)
-			game.DrawLine(gWall)
+			sc.DrawString(p.Add(game.Point{Y: -1}), gWall, wall)
 		}
-		game.DrawLine(fmt.Sprintf("%s%s%s", gWall, strings.Repeat(gSpace, g.size*7-1), gWall))
+		sc.DrawString(game.Point{X: i*4 + 4}, gWall, wall)
+		sc.DrawString(game.Point{X: i*4 + 4, Y: width - 1}, gWall, wall)
+	}
+	sc.DrawString(game.Point{X: g.size * 4}, strings.Repeat(gWall, width), wall)
+}
+
+// style the style of tile `b` in the theme
+func (g *g2048) style(b block) game.Style {
+	if b == 0 {
+		return game.StyleDefault
 	}
-	game.CursorUp(1)
-	game.DrawLine(strings.Repeat(gWall, g.size*7+1))
+	return g.theme.Style(fmt.Sprintf("%s.%d", game.RoleTile, b))
 }
 
 func (g *g2048) getContent(level int, b block) string {
@@ -159,7 +212,6 @@ func (g *g2048) move(rev bool, genFunc func(i int) []block, setFunc func(x int,
 	}
 
 	g.genNext()
-	g.draw()
 }
 
 func (g *g2048) moveLeft() {
@@ -202,49 +254,44 @@ func (g *g2048) setCol(y int, line []block) {
 	}
 }
 
+// doMerge slide the tiles of a line to its start, or to its end if `reverse`, merging each pair
+// of equal tiles once from that side, and score the merged tiles
 func (g *g2048) doMerge(reverse bool, item []block) []block {
 	if reverse {
-		sort.Slice(item, func(i, j int) bool {
-			return i > j
-		})
+		item = reversed(item)
 	}
 
-	target := make([]block, 0)
-
+	target := make([]block, 0, len(item))
+	merged := false // the last tile of target is a merged one
 	for _, b := range item {
-		if b != 0 {
-			target = append(target, b)
+		if b == 0 {
+			continue
 		}
-	}
-
-	var complete bool
-
-	for !complete {
-		complete = true
-		for i := 1; i < len(target); i++ {
-			if target[i] == target[i-1] {
-				complete = false
-				target[i-1] <<= 1
-
-				var tmp []block
-				if i < len(target)-1 {
-					tmp = target[i+1:]
-				}
-				target = append(target[:i], tmp...)
-			}
+		if n := len(target); n > 0 && !merged && target[n-1] == b {
+			target[n-1] <<= 1
+			g.score += int(target[n-1])
+			merged = true
+			continue
 		}
+		target = append(target, b)
+		merged = false
 	}
 
 	// fill zero
-	tmp := make([]block, len(item)-len(target))
+	target = append(target, make([]block, len(item)-len(target))...)
 	if reverse {
-		sort.Slice(target, func(i, j int) bool {
-			return i > j
-		})
-		return append(tmp, target...)
-	} else {
-		return append(target, tmp...)
+		return reversed(target)
+	}
+	return target
+}
+
+// reversed a copy of `line` in the reverse order
+func reversed(line []block) []block {
+	r := make([]block, len(line))
+	for i, b := range line {
+		r[len(line)-1-i] = b
 	}
+	return r
 }
 
 func (g *g2048) genNext() {
@@ -258,11 +305,9 @@ func (g *g2048) genNext() {
 	}
 
 	if len(empty) == 0 {
-		g.stop = true
-		g.msg = "Game over!"
 		return
 	}
 
-	pos := empty[rand.Intn(len(empty))]
+	pos := empty[g.rand.Intn(len(empty))]
 	g.pane[pos[0]][pos[1]] = 2
 }
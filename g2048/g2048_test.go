@@ -0,0 +1,51 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/zhaowk/game"
+	"github.com/zhaowk/game/gametest"
+)
+
+func TestDraw(t *testing.T) {
+	g := &g2048{size: 4, keys: g2048Keys, msg: "Game over!"}
+	g.pane = [][]block{
+		{2, 0, 0, 4},
+		{0, 16, 0, 0},
+		{0, 0, 128, 0},
+		{1024, 0, 0, 2048},
+	}
+
+	vt := gametest.Render(18, 52, func(sc *game.Screen) { game.DrawWidget(sc, game.Point{}, g.layout()) })
+	gametest.Golden(t, "testdata/draw.golden", vt.String())
+
+	if got := vt.Line(14); got != "#@1024@               @2048@#" {
+		t.Errorf("tile line = %q", got)
+	}
+	if c := vt.Cell(14, 23); c.Attr.Bg != gametest.RGB(0xed, 0xc2, 0x2e) {
+		t.Errorf("2048 tile background = %+v", c.Attr.Bg)
+	}
+}
+
+func TestMergeScore(t *testing.T) {
+	tests := []struct {
+		reverse bool
+		in      []block
+		want    []block
+		score   int
+	}{
+		{false, []block{2, 2, 4, 0}, []block{4, 4, 0, 0}, 4},
+		{false, []block{2, 2, 2, 2}, []block{4, 4, 0, 0}, 8},
+		{false, []block{4, 0, 4, 2}, []block{8, 2, 0, 0}, 8},
+		{true, []block{2, 2, 4, 0}, []block{0, 0, 4, 4}, 4},
+		{true, []block{2, 2, 2, 0}, []block{0, 0, 2, 4}, 4},
+		{true, []block{0, 16, 8, 16}, []block{0, 16, 8, 16}, 0},
+	}
+	for _, tt := range tests {
+		g := &g2048{size: 4}
+		if got := g.doMerge(tt.reverse, tt.in); !reflect.DeepEqual(got, tt.want) || g.score != tt.score {
+			t.Errorf("doMerge(%v, %v) = %v scoring %d, want %v scoring %d", tt.reverse, tt.in, got, g.score, tt.want, tt.score)
+		}
+	}
+}
@@ -1,7 +1,23 @@
 package main
 
-import "github.com/zhaowk/game"
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/zhaowk/game"
+)
 
 func main() {
-	game.RunGame(&g2048{})
+	r := game.NewRunner()
+	r.SetName("g2048")
+	r.Flags(flag.CommandLine)
+	flag.Parse()
+
+	res := r.Run(&g2048{})
+	if res.Err != nil {
+		fmt.Fprintln(os.Stderr, res.Err)
+		os.Exit(1)
+	}
+	fmt.Fprintf(os.Stderr, "%v, score %d, seed %d\n", res.Outcome, res.Score, res.Seed)
 }
@@ -1,5 +1,7 @@
 package game
 
+import "context"
+
 // Game interface
 type Game interface {
 	Init(...interface{}) error
@@ -8,19 +10,49 @@ type Game interface {
 	Finish()
 }
 
-// RunGame run Game with args
-func RunGame(g Game, args ...interface{}) {
+// RunGame run Game with args. The terminal stays in raw mode until the game finishes,
+// and is restored on return, on panic and on SIGINT/SIGTERM.
+func RunGame(g Game, args ...interface{}) Result {
+	return RunGameContext(context.Background(), g, args...)
+}
+
+// RunGameContext run Game with args until Next returns false or `ctx` is done
+func RunGameContext(ctx context.Context, g Game, args ...interface{}) Result {
 	if g == nil {
 		panic("empty game")
 	}
+	return NewRunner().RunContext(ctx, &gameAdapter{Game: g}, args...)
+}
+
+// gameAdapter run a Game as an EventGame, keys go to Run until Next returns false
+type gameAdapter struct {
+	Game
+	r *Runner
+}
 
-	if err := g.Init(args...); err != nil {
-		panic(err)
+func (a *gameAdapter) Init(r *Runner, args ...interface{}) error {
+	if err := a.Game.Init(args...); err != nil {
+		return err
 	}
 
-	for g.Next() {
-		g.Run(GetCh())
+	a.r = r
+	r.screen = nil // a Game draws by itself
+	a.check()
+	return nil
+}
+
+func (a *gameAdapter) Update(e Event) {
+	if k, ok := e.(Key); ok {
+		a.Run(k.Code, k.Raw)
 	}
+	a.check()
+}
 
-	g.Finish()
+// Render nothing, a Game draws by itself
+func (a *gameAdapter) Render(*Screen) {}
+
+func (a *gameAdapter) check() {
+	if !a.Next() {
+		a.r.Quit()
+	}
 }
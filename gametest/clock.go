@@ -0,0 +1,104 @@
+package gametest
+
+import (
+	"sync"
+	"time"
+
+	"github.com/zhaowk/game"
+)
+
+// Clock a fake game.Clock, the time only moves by Set and Advance
+type Clock struct {
+	mu      sync.Mutex
+	now     time.Time
+	tickers []*ticker
+}
+
+// NewClock clock stopped at `start`
+func NewClock(start time.Time) *Clock {
+	return &Clock{now: start}
+}
+
+// Now the fake time
+func (c *Clock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.now
+}
+
+// NewTicker ticker firing as Advance passes its ticks
+func (c *Clock) NewTicker(d time.Duration) game.Ticker {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	t := &ticker{clock: c, c: make(chan time.Time, 1), d: d, next: c.now.Add(d)}
+	c.tickers = append(c.tickers, t)
+	return t
+}
+
+// Sleep return at once, advancing the time by `d`
+func (c *Clock) Sleep(d time.Duration) {
+	c.Advance(d)
+}
+
+// Set move the time to `t` without firing tickers
+func (c *Clock) Set(t time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.now = t
+}
+
+// Advance move the time forward by `d`, firing the tickers due in order.
+// Like time.Ticker, a tick is dropped if the previous one was not received.
+func (c *Clock) Advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	target := c.now.Add(d)
+	for {
+		var due *ticker
+		for _, t := range c.tickers {
+			if !t.next.After(target) && (due == nil || t.next.Before(due.next)) {
+				due = t
+			}
+		}
+		if due == nil {
+			break
+		}
+
+		c.now = due.next
+		select {
+		case due.c <- c.now:
+		default:
+		}
+		due.next = due.next.Add(due.d)
+	}
+	c.now = target
+}
+
+type ticker struct {
+	clock *Clock
+	c     chan time.Time
+	d     time.Duration
+	next  time.Time
+}
+
+func (t *ticker) C() <-chan time.Time {
+	return t.c
+}
+
+// Stop remove the ticker from its clock, under the lock of the clock as Advance may run on another goroutine
+func (t *ticker) Stop() {
+	c := t.clock
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for i, o := range c.tickers {
+		if o == t {
+			c.tickers = append(c.tickers[:i], c.tickers[i+1:]...)
+			return
+		}
+	}
+}
@@ -0,0 +1,47 @@
+package gametest
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/zhaowk/game"
+)
+
+// UpdateEnv the environment variable rewriting the golden files when set to 1, as `UPDATE_GOLDEN=1 go test`.
+// A flag would be registered in every binary importing the package.
+const UpdateEnv = "UPDATE_GOLDEN"
+
+// Golden compare `got` with the golden file `path`, run `UPDATE_GOLDEN=1 go test` to rewrite it
+func Golden(t testing.TB, path string, got string) {
+	t.Helper()
+
+	if os.Getenv(UpdateEnv) == "1" {
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(got), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		return
+	}
+
+	want, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("read golden file: %v, run with %s=1 to create it", err, UpdateEnv)
+	}
+	if got != string(want) {
+		t.Errorf("frame differs from %s\n--- got\n%s--- want\n%s", path, got, want)
+	}
+}
+
+// Render draw a frame with `draw` on a screen of `rows` x `cols`, and return the terminal showing it
+func Render(rows, cols int, draw func(s *game.Screen)) *VT {
+	vt := NewVT(rows, cols)
+	s := game.NewScreen(rows, cols)
+	s.SetOutput(game.NewOutput(vt))
+
+	draw(s)
+	_ = s.Flush()
+	return vt
+}
@@ -0,0 +1,105 @@
+package gametest
+
+import (
+	"testing"
+	"time"
+
+	"github.com/zhaowk/game"
+)
+
+// Epoch the start time of the fake clock of a Harness
+var Epoch = time.Date(2022, 9, 21, 0, 0, 0, 0, time.UTC)
+
+// Seed the seed of the runner of a Harness, so a test sees the same game every run
+var Seed int64 = 1
+
+// Harness an EventGame run headless, step by step in virtual time: keys are delivered at once,
+// and waiting delivers the ticks due without sleeping
+type Harness struct {
+	Runner *game.Runner
+	Clock  *Clock
+	VT     *VT
+
+	rate time.Duration // tick rate of the runner
+	last time.Time     // time of the last tick, or when the rate was set
+}
+
+// Start init game `g` with args on a virtual terminal of `rows` x `cols`
+func Start(t testing.TB, g game.EventGame, rows, cols int, args ...interface{}) *Harness {
+	t.Helper()
+
+	h := &Harness{
+		Runner: game.NewRunner(),
+		Clock:  NewClock(Epoch),
+		VT:     NewVT(rows, cols),
+	}
+	h.Runner.SetClock(h.Clock)
+	h.Runner.SetOutput(game.NewOutput(h.VT))
+	h.Runner.SetSize(rows, cols)
+	h.Runner.SetSeed(Seed)
+
+	if err := h.Runner.Start(g, args...); err != nil {
+		t.Fatalf("init game: %v", err)
+	}
+	h.sync()
+	return h
+}
+
+// Play play script `s`
+func (h *Harness) Play(s *Script) {
+	for _, st := range s.steps {
+		h.Press(st.keys...)
+		h.Wait(st.wait)
+	}
+}
+
+// Press deliver `keys` one after another
+func (h *Harness) Press(keys ...game.Key) {
+	for _, k := range keys {
+		h.Dispatch(k.Event())
+	}
+}
+
+// Type deliver the keys of the raw terminal input `text`
+func (h *Harness) Type(text string) {
+	h.Play(NewScript().Type(text))
+}
+
+// Dispatch deliver any event
+func (h *Harness) Dispatch(e game.Event) {
+	h.Runner.Dispatch(e)
+	h.sync()
+}
+
+// Wait let `d` pass on the fake clock, delivering the ticks due
+func (h *Harness) Wait(d time.Duration) {
+	target := h.Clock.Now().Add(d)
+	for h.Runner.Running() && h.rate > 0 {
+		next := h.last.Add(h.rate)
+		if next.After(target) {
+			break
+		}
+
+		h.Clock.Set(next)
+		h.last = next
+		h.Dispatch(game.TickEvent{Time: next})
+	}
+	h.Clock.Set(target)
+}
+
+// Ticks let `n` ticks pass at the current tick rate
+func (h *Harness) Ticks(n int) {
+	h.Wait(time.Duration(n) * h.rate)
+}
+
+// Stop end the game as the runner does, and return the result
+func (h *Harness) Stop() game.Result {
+	return h.Runner.Close()
+}
+
+// sync restart the ticks when the game changed the rate, as the runner loop does
+func (h *Harness) sync() {
+	if rate := h.Runner.Tick(); rate != h.rate {
+		h.rate, h.last = rate, h.Clock.Now()
+	}
+}
@@ -0,0 +1,353 @@
+package gametest
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/zhaowk/game"
+)
+
+// counter counts ticks, `+` doubles the tick rate, `q` quits, `e` is game over, `p` pauses
+type counter struct {
+	r     *game.Runner
+	ticks int
+	keys  string
+	menu  bool // enable the game menu, New Game resets the ticks
+
+	render func() // called on every frame if set
+}
+
+func (c *counter) Init(r *game.Runner, _ ...interface{}) error {
+	c.r = r
+	r.SetTick(time.Second)
+	if _, err := r.Keymap(game.Keymap{
+		{Action: game.ActionPause, Keys: []game.Key{game.KeyRune('p')}},
+		{Action: game.ActionDown, Keys: []game.Key{game.KeyRune('j')}},
+	}); err != nil {
+		return err
+	}
+	if c.menu {
+		r.EnableMenu(func() { c.ticks = 0 })
+	}
+	return nil
+}
+
+func (c *counter) Update(e game.Event) {
+	switch e := e.(type) {
+	case game.TickEvent:
+		c.ticks++
+		c.r.SetScore(c.ticks)
+	case game.Key:
+		c.keys += e.String()
+		switch e.Code {
+		case '+':
+			c.r.SetTick(c.r.Tick() / 2)
+		case 'q':
+			c.r.Quit()
+		case 'e':
+			c.r.GameOver(game.OutcomeLose)
+		}
+	}
+}
+
+func (c *counter) Render(s *game.Screen) {
+	if c.render != nil {
+		c.render()
+	}
+	s.DrawString(game.Point{}, fmt.Sprintf("ticks: %d keys: %s", c.ticks, c.keys), game.StyleDefault)
+}
+
+func (c *counter) Finish() {}
+
+func TestHarness(t *testing.T) {
+	c := &counter{}
+	h := Start(t, c, 2, 40)
+
+	h.Wait(2500 * time.Millisecond)
+	if c.ticks != 2 {
+		t.Fatalf("ticks = %d after 2.5s, want 2", c.ticks)
+	}
+
+	h.Play(NewScript().Type("a\x1b[A+").Wait(time.Second).Type("q").Wait(time.Hour))
+	if c.ticks != 4 {
+		t.Errorf("ticks = %d after doubling the rate for 1s, want 4", c.ticks)
+	}
+
+	res := h.Stop()
+	if got := h.VT.Line(0); got != "ticks: 4 keys: aUp+q" {
+		t.Errorf("final frame = %q", got)
+	}
+	if res.Outcome != game.OutcomeQuit || res.Score != 4 || res.Duration != time.Hour+3500*time.Millisecond || res.Seed != Seed {
+		t.Errorf("result = %+v", res)
+	}
+}
+
+func TestTooSmall(t *testing.T) {
+	c := &counter{}
+	h := Start(t, c, 5, 40)
+	h.Runner.SetMinSize(5, 30)
+
+	h.Dispatch(game.ResizeEvent{Rows: 4, Cols: 40})
+	if got := h.VT.Line(0); got != "           Terminal too small" {
+		t.Errorf("line 0 = %q, want the overlay", got)
+	}
+	h.Ticks(3)
+	h.Type("ab")
+	if c.ticks != 0 || c.keys != "" {
+		t.Errorf("ticks = %d, keys = %q while too small, want none", c.ticks, c.keys)
+	}
+
+	h.Dispatch(game.ResizeEvent{Rows: 5, Cols: 30})
+	h.Ticks(1)
+	h.Type("c")
+	if got := h.VT.String(); got != "ticks: 1 keys: c\n" {
+		t.Errorf("screen after resize = %q", got)
+	}
+	if rows, cols := h.Runner.Size(); rows != 5 || cols != 30 {
+		t.Errorf("size = %dx%d", rows, cols)
+	}
+}
+
+func TestMenu(t *testing.T) {
+	c := &counter{menu: true}
+	h := Start(t, c, 12, 40)
+	esc := game.KeyCode(game.SysEsc)
+
+	if got := h.VT.String(); !strings.Contains(got, "New Game") || strings.Contains(got, "Resume") {
+		t.Fatalf("screen:\n%s\nwant the start menu", got)
+	}
+	h.Ticks(2)
+	h.Type("\r")
+	h.Ticks(2)
+	if c.ticks != 2 || c.keys != "" {
+		t.Fatalf("ticks = %d, keys = %q, want the ticks held and the keys taken by the menu", c.ticks, c.keys)
+	}
+
+	// Esc opens the menu on Resume, New Game is above
+	h.Press(esc)
+	if got := h.VT.Line(5); !strings.Contains(got, "Resume") {
+		t.Errorf("line 5 = %q, want Resume", got)
+	}
+	if c := h.VT.Cell(5, 15); !c.Attr.Reverse {
+		t.Errorf("resume cell = %+v, want reversed", c.Attr)
+	}
+	h.Type("\x1b[A\r")
+	if c.ticks != 0 || h.Runner.Modal() != nil {
+		t.Errorf("ticks = %d, modal = %v after New Game", c.ticks, h.Runner.Modal())
+	}
+
+	// Settings toggle the colors, Esc goes back to the menu. The menu moves down on the key of ActionDown, not s.
+	h.Press(esc)
+	h.Type("sj\r\r")
+	if got := h.VT.String(); !strings.Contains(got, "Colors: none") {
+		t.Errorf("screen:\n%s\nwant the colors turned off", got)
+	}
+	h.Press(esc)
+	h.Press(esc)
+	if h.Runner.Modal() != nil {
+		t.Error("menu not closed by Esc")
+	}
+
+	h.Type("e")
+	if got := h.VT.String(); !strings.Contains(got, "Game over — play again? (y/n)") {
+		t.Fatalf("screen:\n%s\nwant the dialog", got)
+	}
+	h.Type("y")
+	if !h.Runner.Running() || c.ticks != 0 {
+		t.Fatalf("running = %v, ticks = %d after playing again", h.Runner.Running(), c.ticks)
+	}
+
+	h.Ticks(1)
+	h.Type("e\x1b[Cn")
+	if res := h.Stop(); res.Outcome != game.OutcomeLose || res.Score != 1 {
+		t.Errorf("result = %+v", res)
+	}
+}
+
+func TestPause(t *testing.T) {
+	c := &counter{menu: true}
+	h := Start(t, c, 12, 40)
+	h.Type("\r")
+
+	h.Type("p")
+	if got := h.VT.String(); !strings.Contains(got, "PAUSED") || !strings.Contains(got, "p to resume") {
+		t.Fatalf("screen:\n%s\nwant the paused overlay", got)
+	}
+	h.Type("a")
+	h.Ticks(3)
+	if c.ticks != 0 || c.keys != "" {
+		t.Errorf("ticks = %d, keys = %q while paused", c.ticks, c.keys)
+	}
+
+	h.Type("P") // in any case
+	h.Ticks(2)
+	if c.ticks != 2 || strings.Contains(h.VT.String(), "PAUSED") {
+		t.Errorf("ticks = %d after resuming, screen:\n%s", c.ticks, h.VT.String())
+	}
+
+	// losing the focus pauses, Resume of the menu resumes
+	h.Type("\x1b[O")
+	if !h.Runner.Paused() {
+		t.Fatal("not paused when the focus is lost")
+	}
+	h.Type("\x1b[I")
+	h.Press(game.KeyCode(game.SysEsc))
+	h.Type("\r")
+	h.Ticks(1)
+	if h.Runner.Paused() || c.ticks != 3 {
+		t.Errorf("paused = %v, ticks = %d after Resume", h.Runner.Paused(), c.ticks)
+	}
+}
+
+func TestKeymapFile(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", dir)
+	defaults := game.Keymap{{Action: game.ActionMenu, Keys: []game.Key{game.KeyCode(game.SysEsc)}, Help: "menu"}}
+
+	r := game.NewRunner()
+	if km, err := r.Keymap(defaults); err != nil || len(km) != 1 || len(km[0].Keys) != 1 {
+		t.Errorf("keymap = %v, %v without a name, want the defaults", km, err)
+	}
+
+	r.SetName("counter")
+	if km, err := r.Keymap(defaults); err != nil || len(km[0].Keys) != 1 {
+		t.Errorf("keymap = %v, %v without a file, want the defaults", km, err)
+	}
+
+	path := filepath.Join(dir, "counter", "keys.toml")
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte("menu = \"m\"\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	km, err := r.Keymap(defaults)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if km.Action(game.KeyRune('m')) != game.ActionMenu || km.Action(game.KeyCode(game.SysEsc)) != "" {
+		t.Errorf("keymap = %v, want the menu on m only", km)
+	}
+
+	if err := os.WriteFile(path, []byte("menu = m\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := r.Keymap(defaults); err == nil || !strings.Contains(err.Error(), "keys.toml: line 1") {
+		t.Errorf("error = %v, want the file and line", err)
+	}
+
+	// the game does not start, and the terminal is restored
+	vt := NewVT(2, 40)
+	r.SetInput(NewScript())
+	r.SetOutput(game.NewOutput(vt))
+	r.SetSize(2, 40)
+	if res := r.Run(&counter{}); res.Err == nil || !strings.Contains(res.Err.Error(), "keys.toml: line 1") {
+		t.Errorf("Run error = %v, want the file and line", res.Err)
+	}
+	if vt.Mode(1049) || !vt.Mode(25) {
+		t.Error("alternate screen or hidden cursor left on")
+	}
+}
+
+func TestScriptInput(t *testing.T) {
+	c := &counter{}
+	vt := NewVT(2, 40)
+
+	r := game.NewRunner()
+	r.SetInput(NewScript().Type("xy").Wait(10 * time.Millisecond).Type("z"))
+	r.SetOutput(game.NewOutput(vt))
+	r.SetClock(NewClock(Epoch))
+	r.SetSize(2, 40)
+
+	res := r.Run(c)
+	if res.Outcome != game.OutcomeInterrupted {
+		t.Errorf("outcome = %v when the script ends, want interrupted", res.Outcome)
+	}
+	if c.keys != "xyz" || c.ticks != 0 {
+		t.Errorf("keys = %q, ticks = %d", c.keys, c.ticks)
+	}
+	if res.Seed != Epoch.UnixNano() {
+		t.Errorf("seed = %d, want one from the clock", res.Seed)
+	}
+	if vt.Mode(1049) || !vt.Mode(25) {
+		t.Error("alternate screen or hidden cursor left on")
+	}
+}
+
+// panicker panics on any key
+type panicker struct{ counter }
+
+func (p *panicker) Update(e game.Event) {
+	if _, ok := e.(game.Key); ok {
+		panic("boom")
+	}
+}
+
+func TestRestoreOnPanic(t *testing.T) {
+	var modes []bool
+	vt := NewVT(2, 40)
+
+	r := game.NewRunner()
+	r.SetInput(NewScript().Type("x"))
+	r.SetOutput(game.NewOutput(vt))
+	r.SetClock(NewClock(Epoch))
+	r.SetSize(2, 40)
+
+	func() {
+		defer func() {
+			if recover() == nil {
+				t.Error("panic not passed on")
+			}
+		}()
+		r.Run(&panicker{counter{render: func() { modes = []bool{vt.Mode(1049), vt.Mode(25)} }}})
+	}()
+
+	if len(modes) != 2 || !modes[0] || modes[1] {
+		t.Errorf("alternate screen, cursor = %v while running, want [true false]", modes)
+	}
+	if vt.Mode(1049) || !vt.Mode(25) {
+		t.Error("alternate screen or hidden cursor left on after panic")
+	}
+}
+
+func TestClock(t *testing.T) {
+	c := NewClock(Epoch)
+	tk := c.NewTicker(time.Second)
+
+	c.Advance(500 * time.Millisecond)
+	select {
+	case <-tk.C():
+		t.Fatal("tick before it is due")
+	default:
+	}
+
+	c.Advance(time.Second)
+	if got := <-tk.C(); !got.Equal(Epoch.Add(time.Second)) {
+		t.Errorf("tick at %v", got)
+	}
+	if !c.Now().Equal(Epoch.Add(1500 * time.Millisecond)) {
+		t.Errorf("now = %v", c.Now())
+	}
+
+	tk.Stop()
+	c.Advance(time.Minute)
+	select {
+	case <-tk.C():
+		t.Error("tick after Stop")
+	default:
+	}
+
+	// stopped on another goroutine as the runner does, while the test advances the clock
+	tk = c.NewTicker(time.Millisecond)
+	done := make(chan struct{})
+	go func() {
+		tk.Stop()
+		close(done)
+	}()
+	c.Advance(time.Second)
+	<-done
+}
@@ -0,0 +1,89 @@
+package gametest
+
+import (
+	"sync"
+	"time"
+
+	"github.com/zhaowk/game"
+)
+
+// Script keys to press with delays between them, played by Harness.Play in virtual time,
+// or replayed as a game.Input with real delays
+type Script struct {
+	steps []step
+
+	once  sync.Once
+	close sync.Once
+	keys  chan game.Key
+	done  chan struct{}
+}
+
+type step struct {
+	keys []game.Key
+	wait time.Duration
+}
+
+// NewScript empty script
+func NewScript() *Script {
+	return &Script{}
+}
+
+// Press press `keys` one after another
+func (s *Script) Press(keys ...game.Key) *Script {
+	s.steps = append(s.steps, step{keys: keys})
+	return s
+}
+
+// Type press the keys of the raw terminal input `text`, e.g. "w" or "\x1b[A" for Up
+func (s *Script) Type(text string) *Script {
+	var d game.Decoder
+	keys := append(d.Feed([]byte(text)), d.Flush()...)
+	return s.Press(keys...)
+}
+
+// Wait let `d` pass
+func (s *Script) Wait(d time.Duration) *Script {
+	s.steps = append(s.steps, step{wait: d})
+	return s
+}
+
+// Keys replay the script in real time, closed at its end
+func (s *Script) Keys() <-chan game.Key {
+	s.once.Do(func() {
+		s.keys = make(chan game.Key)
+		s.done = make(chan struct{})
+		go s.replay()
+	})
+	return s.keys
+}
+
+// Close stop replaying
+func (s *Script) Close() error {
+	s.Keys()
+	s.close.Do(func() {
+		close(s.done)
+	})
+	return nil
+}
+
+func (s *Script) replay() {
+	defer close(s.keys)
+
+	for _, st := range s.steps {
+		for _, k := range st.keys {
+			select {
+			case s.keys <- k:
+			case <-s.done:
+				return
+			}
+		}
+
+		if st.wait > 0 {
+			select {
+			case <-time.After(st.wait):
+			case <-s.done:
+				return
+			}
+		}
+	}
+}
@@ -0,0 +1,465 @@
+// Package gametest helps testing games without a terminal
+package gametest
+
+import (
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/zhaowk/game"
+)
+
+// ColorKind how a Color is given
+type ColorKind uint8
+
+const (
+	ColorDefault ColorKind = iota
+	ColorIndexed           // 0-7, or 8-15 for the bright colors
+	Color256
+	ColorRGB
+)
+
+// Color a foreground or background color
+type Color struct {
+	Kind  ColorKind
+	Value uint32 // index, or 0xRRGGBB
+}
+
+// Indexed color of 8 colors, 0-7 for ColorBlack, ..., ColorWhite, 8-15 for the bright ones
+func Indexed(n uint8) Color {
+	return Color{Kind: ColorIndexed, Value: uint32(n)}
+}
+
+// Palette color of 256 colors
+func Palette(n uint8) Color {
+	return Color{Kind: Color256, Value: uint32(n)}
+}
+
+// RGB true color
+func RGB(r, g, b uint8) Color {
+	return Color{Kind: ColorRGB, Value: uint32(r)<<16 | uint32(g)<<8 | uint32(b)}
+}
+
+// Attr sgr attributes of a cell
+type Attr struct {
+	Fg, Bg    Color
+	Bold      bool
+	Faint     bool
+	Italic    bool
+	Underline bool
+	Blink     bool
+	Reverse   bool
+	Hidden    bool
+	Strike    bool
+}
+
+// Cell a character of the virtual terminal, a wide character takes two cells, the right one has Rune 0
+type Cell struct {
+	Rune rune
+	Comb string // combining marks, variation selectors and ZWJ sequences shown with Rune
+	Attr Attr
+}
+
+var blank = Cell{Rune: ' '}
+
+// VT an in-memory VT100 terminal, write the ANSI stream to it and inspect the cells.
+// It understands the sequences output.go sends: CUP, cursor moves, ED, EL, SGR with
+// 8/256/RGB colors, save/restore cursor and the private modes.
+type VT struct {
+	rows, cols int
+	cells      [][]Cell
+	row, col   int
+	saved      [2]int
+	wrap       bool // the cursor is past the last column
+	attr       Attr
+	modes      map[int]bool
+	pending    []byte // incomplete sequence or rune from the previous Write
+}
+
+// NewVT virtual terminal of `rows` x `cols`
+func NewVT(rows, cols int) *VT {
+	v := &VT{rows: rows, cols: cols, modes: map[int]bool{}}
+	v.cells = make([][]Cell, rows)
+	for i := range v.cells {
+		v.cells[i] = make([]Cell, cols)
+	}
+	v.erase(0, 0, rows-1, cols-1)
+	return v
+}
+
+// Size rows and cols
+func (v *VT) Size() (rows, cols int) {
+	return v.rows, v.cols
+}
+
+// Cell the cell at (row, col) starts with (0, 0) from left-top, like game.Point
+func (v *VT) Cell(row, col int) Cell {
+	if row < 0 || row >= v.rows || col < 0 || col >= v.cols {
+		return blank
+	}
+	return v.cells[row][col]
+}
+
+// Cursor position of the cursor
+func (v *VT) Cursor() (row, col int) {
+	return v.row, v.col
+}
+
+// Mode whether the private mode `n` is set, e.g. 25 for the visible cursor
+func (v *VT) Mode(n int) bool {
+	return v.modes[n]
+}
+
+// Line text of the row without trailing blanks
+func (v *VT) Line(row int) string {
+	if row < 0 || row >= v.rows {
+		return ""
+	}
+
+	var b strings.Builder
+	for _, c := range v.cells[row] {
+		if c.Rune != 0 {
+			b.WriteRune(c.Rune)
+			b.WriteString(c.Comb)
+		}
+	}
+	return strings.TrimRight(b.String(), " ")
+}
+
+// String text of the screen, lines without trailing blanks, trailing empty lines dropped
+func (v *VT) String() string {
+	lines := make([]string, v.rows)
+	for i := range lines {
+		lines[i] = v.Line(i)
+	}
+	return strings.TrimRight(strings.Join(lines, "\n"), "\n") + "\n"
+}
+
+// Write interpret the ANSI stream `p`
+func (v *VT) Write(p []byte) (int, error) {
+	b := append(v.pending, p...)
+	v.pending = nil
+
+	for len(b) > 0 {
+		n := v.step(b)
+		if n == 0 { // incomplete, wait for the next write
+			v.pending = append([]byte(nil), b...)
+			break
+		}
+		b = b[n:]
+	}
+	return len(p), nil
+}
+
+// step interpret the first rune or sequence of `b`, return the bytes used, 0 if incomplete
+func (v *VT) step(b []byte) int {
+	switch c := b[0]; {
+	case c == 0x1b:
+		return v.escape(b)
+	case c == '\n':
+		v.lineFeed()
+		v.col = 0 // onlcr, as the tty output is not raw
+	case c == '\r':
+		v.col, v.wrap = 0, false
+	case c == '\b':
+		if v.col > 0 {
+			v.col--
+		}
+		v.wrap = false
+	case c == '\t':
+		v.col = (v.col/8 + 1) * 8
+		if v.col >= v.cols {
+			v.col = v.cols - 1
+		}
+	case c < ' ' || c == 0x7f:
+		// ignore other controls
+	default:
+		if !utf8.FullRune(b) {
+			return 0
+		}
+		r, n := utf8.DecodeRune(b)
+		v.put(r)
+		return n
+	}
+	return 1
+}
+
+func (v *VT) escape(b []byte) int {
+	if len(b) < 2 {
+		return 0
+	}
+
+	switch b[1] {
+	case '[':
+		return v.csi(b)
+	case '7':
+		v.saved = [2]int{v.row, v.col}
+	case '8':
+		v.row, v.col = v.saved[0], v.saved[1]
+	case 'c':
+		*v = *NewVT(v.rows, v.cols)
+	}
+	return 2
+}
+
+func (v *VT) csi(b []byte) int {
+	i := 2
+	for i < len(b) && b[i] >= 0x20 && b[i] <= 0x3f { // params and intermediates
+		i++
+	}
+	if i >= len(b) {
+		return 0
+	}
+
+	params, final := string(b[2:i]), b[i]
+	private := strings.HasPrefix(params, "?")
+	args := parseArgs(strings.TrimPrefix(params, "?"))
+	arg := func(n, dft int) int {
+		if n < len(args) && args[n] > 0 {
+			return args[n]
+		}
+		return dft
+	}
+
+	v.wrap = false
+	switch final {
+	case 'H', 'f':
+		v.moveTo(arg(0, 1)-1, arg(1, 1)-1)
+	case 'A':
+		v.moveTo(v.row-arg(0, 1), v.col)
+	case 'B':
+		v.moveTo(v.row+arg(0, 1), v.col)
+	case 'C':
+		v.moveTo(v.row, v.col+arg(0, 1))
+	case 'D':
+		v.moveTo(v.row, v.col-arg(0, 1))
+	case 'E':
+		v.moveTo(v.row+arg(0, 1), 0)
+	case 'F':
+		v.moveTo(v.row-arg(0, 1), 0)
+	case 'G':
+		v.moveTo(v.row, arg(0, 1)-1)
+	case 'J':
+		v.eraseDisplay(arg(0, 0))
+	case 'K':
+		v.eraseLine(arg(0, 0))
+	case 'S':
+		v.scroll(arg(0, 1))
+	case 'T':
+		v.scroll(-arg(0, 1))
+	case 'm':
+		v.sgr(args)
+	case 's':
+		v.saved = [2]int{v.row, v.col}
+	case 'u':
+		v.row, v.col = v.saved[0], v.saved[1]
+	case 'h', 'l':
+		if private {
+			for _, n := range args {
+				v.modes[n] = final == 'h'
+			}
+		}
+	}
+	return i + 1
+}
+
+// put rune `r` at the cursor by its display width, a zero width rune or one after ZWJ joins the previous character
+func (v *VT) put(r rune) {
+	w := game.RuneWidth(r)
+	if prev := v.previous(); prev != nil && (w == 0 && r >= 0xa0 || strings.HasSuffix(prev.Comb, "\u200d")) {
+		prev.Comb += string(r)
+		return
+	}
+	if w == 0 {
+		return
+	}
+
+	if v.wrap || v.col+w > v.cols {
+		v.lineFeed()
+		v.col, v.wrap = 0, false
+	}
+
+	v.cells[v.row][v.col] = Cell{Rune: r, Attr: v.attr}
+	if w > 1 {
+		v.cells[v.row][v.col+1] = Cell{Attr: v.attr}
+	}
+	if v.col+w < v.cols {
+		v.col += w
+	} else {
+		v.col, v.wrap = v.cols-1, true
+	}
+}
+
+// previous the character before the cursor on the row, nil if none
+func (v *VT) previous() *Cell {
+	col := v.col - 1
+	if v.wrap {
+		col = v.col
+	}
+	if col >= 0 && v.cells[v.row][col].Rune == 0 {
+		col--
+	}
+	if col < 0 || v.cells[v.row][col].Rune == 0 {
+		return nil
+	}
+	return &v.cells[v.row][col]
+}
+
+func (v *VT) moveTo(row, col int) {
+	v.row, v.col = clamp(row, 0, v.rows-1), clamp(col, 0, v.cols-1)
+}
+
+func (v *VT) lineFeed() {
+	if v.row+1 < v.rows {
+		v.row++
+	} else {
+		v.scroll(1)
+	}
+	v.wrap = false
+}
+
+// scroll move the content up by `n` lines, down if `n` is negative
+func (v *VT) scroll(n int) {
+	for ; n > 0; n-- {
+		copy(v.cells, v.cells[1:])
+		v.cells[v.rows-1] = make([]Cell, v.cols)
+		v.erase(v.rows-1, 0, v.rows-1, v.cols-1)
+	}
+	for ; n < 0; n++ {
+		copy(v.cells[1:], v.cells)
+		v.cells[0] = make([]Cell, v.cols)
+		v.erase(0, 0, 0, v.cols-1)
+	}
+}
+
+func (v *VT) eraseDisplay(mode int) {
+	switch mode {
+	case 0:
+		v.erase(v.row, v.col, v.rows-1, v.cols-1)
+	case 1:
+		v.erase(0, 0, v.row, v.col)
+	case 2, 3:
+		v.erase(0, 0, v.rows-1, v.cols-1)
+	}
+}
+
+func (v *VT) eraseLine(mode int) {
+	switch mode {
+	case 0:
+		v.erase(v.row, v.col, v.row, v.cols-1)
+	case 1:
+		v.erase(v.row, 0, v.row, v.col)
+	case 2:
+		v.erase(v.row, 0, v.row, v.cols-1)
+	}
+}
+
+// erase blank the cells from (r0, c0) to (r1, c1) inclusive, in reading order
+func (v *VT) erase(r0, c0, r1, c1 int) {
+	for r := r0; r <= r1; r++ {
+		from, to := 0, v.cols-1
+		if r == r0 {
+			from = c0
+		}
+		if r == r1 {
+			to = c1
+		}
+		for c := from; c <= to; c++ {
+			v.cells[r][c] = Cell{Rune: ' ', Attr: Attr{Bg: v.attr.Bg}}
+		}
+	}
+}
+
+func (v *VT) sgr(args []int) {
+	if len(args) == 0 {
+		v.attr = Attr{}
+		return
+	}
+
+	for i := 0; i < len(args); i++ {
+		switch n := args[i]; {
+		case n == 0:
+			v.attr = Attr{}
+		case n == 1:
+			v.attr.Bold = true
+		case n == 2:
+			v.attr.Faint = true
+		case n == 3:
+			v.attr.Italic = true
+		case n == 4:
+			v.attr.Underline = true
+		case n == 5, n == 6:
+			v.attr.Blink = true
+		case n == 7:
+			v.attr.Reverse = true
+		case n == 8:
+			v.attr.Hidden = true
+		case n == 9:
+			v.attr.Strike = true
+		case n == 21, n == 22:
+			v.attr.Bold, v.attr.Faint = false, false
+		case n == 23:
+			v.attr.Italic = false
+		case n == 24:
+			v.attr.Underline = false
+		case n == 25, n == 26:
+			v.attr.Blink = false
+		case n == 27:
+			v.attr.Reverse = false
+		case n == 28:
+			v.attr.Hidden = false
+		case n == 29:
+			v.attr.Strike = false
+		case n >= 30 && n <= 37:
+			v.attr.Fg = Indexed(uint8(n - 30))
+		case n == 38:
+			v.attr.Fg, i = extendedColor(args, i)
+		case n == 39:
+			v.attr.Fg = Color{}
+		case n >= 40 && n <= 47:
+			v.attr.Bg = Indexed(uint8(n - 40))
+		case n == 48:
+			v.attr.Bg, i = extendedColor(args, i)
+		case n == 49:
+			v.attr.Bg = Color{}
+		case n >= 90 && n <= 97:
+			v.attr.Fg = Indexed(uint8(n - 90 + 8))
+		case n >= 100 && n <= 107:
+			v.attr.Bg = Indexed(uint8(n - 100 + 8))
+		}
+	}
+}
+
+// extendedColor parse 38;5;n or 38;2;r;g;b at args[i], return the color and the last index used
+func extendedColor(args []int, i int) (Color, int) {
+	if i+2 < len(args) && args[i+1] == 5 {
+		return Palette(uint8(args[i+2])), i + 2
+	}
+	if i+4 < len(args) && args[i+1] == 2 {
+		return RGB(uint8(args[i+2]), uint8(args[i+3]), uint8(args[i+4])), i + 4
+	}
+	return Color{}, len(args)
+}
+
+func parseArgs(params string) []int {
+	if params == "" {
+		return nil
+	}
+
+	fields := strings.Split(params, ";")
+	args := make([]int, len(fields))
+	for i, f := range fields {
+		args[i], _ = strconv.Atoi(f)
+	}
+	return args
+}
+
+func clamp(n, lo, hi int) int {
+	if n < lo {
+		return lo
+	}
+	if n > hi {
+		return hi
+	}
+	return n
+}
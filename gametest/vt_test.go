@@ -0,0 +1,113 @@
+package gametest
+
+import (
+	"testing"
+
+	"github.com/zhaowk/game"
+)
+
+func TestVTOutput(t *testing.T) {
+	vt := NewVT(5, 20)
+	o := game.NewOutput(vt)
+
+	o.Clear()
+	o.DrawAt(game.Point{X: 1, Y: 2}, "hello")
+	o.DrawColor8(game.Foreground, game.ColorRed, "@")
+	o.DrawLine("!")
+	o.Draw("next")
+	o.CursorSave()
+	o.DrawAt(game.Point{X: 4}, "bottom")
+	o.CursorRestore()
+	o.DrawColorRGB(game.Background, game.RGB{1, 2, 3}, "x")
+	o.DrawColor256(game.Foreground, 200, "y")
+	o.DrawSgr("b", game.SgrBold, game.SgrUnderLine)
+
+	want := "\n  hello@!\nnextxyb\n\nbottom\n"
+	if got := vt.String(); got != want {
+		t.Errorf("screen\n%s\nwant\n%s", got, want)
+	}
+
+	if c := vt.Cell(1, 7); c.Rune != '@' || c.Attr.Fg != Indexed(uint8(game.ColorRed)) {
+		t.Errorf("cell (1, 7) = %+v, want a red '@'", c)
+	}
+	if c := vt.Cell(1, 8); c.Attr != (Attr{}) {
+		t.Errorf("cell (1, 8) = %+v, want reset attributes", c)
+	}
+	if c := vt.Cell(2, 4); c.Attr.Bg != RGB(1, 2, 3) {
+		t.Errorf("cell (2, 4) = %+v, want rgb background", c)
+	}
+	if c := vt.Cell(2, 5); c.Attr.Fg != Palette(200) {
+		t.Errorf("cell (2, 5) = %+v, want 256 color", c)
+	}
+	if c := vt.Cell(2, 6); !c.Attr.Bold || !c.Attr.Underline {
+		t.Errorf("cell (2, 6) = %+v, want bold underline", c)
+	}
+}
+
+func TestVTErase(t *testing.T) {
+	vt := NewVT(3, 5)
+	vt.Write([]byte("abcde\r\nfghij\r\nklmno"))
+
+	vt.Write([]byte("\x1b[2;3H\x1b[0K"))
+	vt.Write([]byte("\x1b[1;2H\x1b[1K"))
+	if got, want := vt.String(), "  cde\nfg\nklmno\n"; got != want {
+		t.Errorf("erase line %q, want %q", got, want)
+	}
+
+	vt.Write([]byte("\x1b[3;4H\x1b[1J"))
+	if got, want := vt.String(), "\n\n    o\n"; got != want {
+		t.Errorf("erase display %q, want %q", got, want)
+	}
+}
+
+func TestVTSplitWrites(t *testing.T) {
+	vt := NewVT(2, 10)
+	stream := []byte("\x1b[2;2H\x1b[31m贪吃蛇\x1b[?25l")
+	for i := range stream { // byte by byte
+		vt.Write(stream[i : i+1])
+	}
+
+	if got := vt.Line(1); got != " 贪吃蛇" {
+		t.Errorf("line = %q", got)
+	}
+	if vt.Mode(25) {
+		t.Error("cursor still visible")
+	}
+}
+
+func TestVTWrapAndScroll(t *testing.T) {
+	vt := NewVT(2, 3)
+	vt.Write([]byte("abcdefg"))
+
+	if got, want := vt.String(), "def\ng\n"; got != want {
+		t.Errorf("screen %q, want %q", got, want)
+	}
+}
+
+func TestRenderGolden(t *testing.T) {
+	vt := Render(3, 12, func(s *game.Screen) {
+		s.DrawString(game.Point{}, "+--------+", game.StyleDefault)
+		s.DrawString(game.Point{X: 1}, "| golden |", game.StyleDefault.Bold())
+		s.DrawString(game.Point{X: 2}, "+--------+", game.StyleDefault)
+	})
+
+	Golden(t, "testdata/render.golden", vt.String())
+	if !vt.Cell(1, 2).Attr.Bold {
+		t.Error("styled cell not bold")
+	}
+}
+
+func TestVTWide(t *testing.T) {
+	vt := NewVT(2, 5)
+	vt.Write([]byte("中é文字"))
+
+	if got, want := vt.String(), "中é文\n字\n"; got != want {
+		t.Errorf("screen %q, want %q", got, want)
+	}
+	if c := vt.Cell(0, 1); c.Rune != 0 {
+		t.Errorf("right half = %q, want 0", c.Rune)
+	}
+	if c := vt.Cell(0, 2); c.Rune != 'e' || c.Comb != "́" {
+		t.Errorf("combined cell = %q %q", c.Rune, c.Comb)
+	}
+}
@@ -3,17 +3,10 @@
 package game
 
 import (
-	"golang.org/x/sys/unix"
-	"os"
-	"os/signal"
-)
+	"fmt"
+	"sync"
 
-const (
-	SysUp = 1000 + iota
-	SysDown
-	SysLeft
-	SysRight
-	SysParse
+	"golang.org/x/sys/unix"
 )
 
 const (
@@ -30,62 +23,62 @@ const (
 	vtime  = unix.VTIME
 )
 
-// GetCh get char from tty
-func GetCh() (int, string) {
-	var (
-		in              int
-		err             error
-		sigio           = make(chan os.Signal)
-		originalTermios *unix.Termios
-	)
+// Input a source of keys, such as a Terminal, or a scripted one in tests
+type Input interface {
+	// Keys key events, closed when the input ends
+	Keys() <-chan Key
+	// Close stop the input
+	Close() error
+}
 
-	if in, err = unix.Open("/dev/tty", unix.O_RDONLY, 0); err != nil {
-		panic(err)
-	}
+var (
+	stdMu sync.Mutex
+	stdIn Input
+)
 
-	signal.Notify(sigio, unix.SIGIO)
+// SetInput set the input of GetCh and RunGame, the session terminal is opened on first use if not set
+func SetInput(in Input) {
+	stdMu.Lock()
+	defer stdMu.Unlock()
 
-	_, _ = unix.FcntlInt(uintptr(in), unix.F_SETFL, unix.O_ASYNC|unix.O_NONBLOCK)
-	originalTermios, err = unix.IoctlGetTermios(in, tcGetRequest)
+	stdIn = in
+}
 
-	tios := *originalTermios
-	tios.Iflag &^= brkInt | ixon
-	tios.Lflag &^= echo | icanon | isig | iexten
-	tios.Cflag &^= cSize | parenb
-	tios.Cflag |= cs8
-	tios.Cc[vmin] = 1
-	tios.Cc[vtime] = 0
+// GetCh get char from the input, the session terminal stays in raw mode until RunGame returns
+func GetCh() (int, string) {
+	in, err := stdInput()
+	if err != nil {
+		panic(err)
+	}
+	k, ok := <-in.Keys()
+	if !ok {
+		return 0, ""
+	}
+	return k.Code, k.Raw
+}
 
-	err = unix.IoctlSetTermios(in, tcSetRequest, &tios)
-	defer func() {
-		err = unix.IoctlSetTermios(in, tcSetRequest, originalTermios)
-		_ = unix.Close(in)
-	}()
-	buf := make([]byte, 128)
+// stdInput the input set by SetInput, or the session terminal opened on first use
+func stdInput() (Input, error) {
+	stdMu.Lock()
+	defer stdMu.Unlock()
 
-LOOP:
-	<-sigio
-	n, err := unix.Read(in, buf)
-	if err == unix.EAGAIN || err == unix.EWOULDBLOCK {
-		goto LOOP
-	}
-	if n == 1 {
-		return int(buf[0]), ""
-	} else if n == 3 {
-		switch buf[2] {
-		case 65:
-			return SysUp, ""
-		case 66:
-			return SysDown, ""
-		case 67:
-			return SysRight, ""
-		case 68:
-			return SysLeft, ""
-		default:
-			return SysParse, string(buf[0:n])
+	if stdIn == nil {
+		t, err := OpenTerminal()
+		if err != nil {
+			return nil, fmt.Errorf("open the terminal: %v", err)
 		}
-	} else {
-		return SysParse, string(buf[0:n])
+		stdIn = t
+	}
+	return stdIn, nil
+}
+
+// closeStdInput close the input if it was opened, restoring the session terminal
+func closeStdInput() {
+	stdMu.Lock()
+	defer stdMu.Unlock()
+
+	if stdIn != nil {
+		_ = stdIn.Close()
+		stdIn = nil
 	}
-	return 0, ""
 }
@@ -0,0 +1,123 @@
+package game
+
+import "strings"
+
+const (
+	SysUp = 1000 + iota
+	SysDown
+	SysLeft
+	SysRight
+	SysParse
+	SysHome
+	SysEnd
+	SysPgUp
+	SysPgDn
+	SysInsert
+	SysDelete
+	SysF1
+	SysF2
+	SysF3
+	SysF4
+	SysF5
+	SysF6
+	SysF7
+	SysF8
+	SysF9
+	SysF10
+	SysF11
+	SysF12
+	SysRune
+	SysMouse
+	SysFocusIn  // the terminal gained the focus, reported after EnableFocus
+	SysFocusOut // the terminal lost the focus
+)
+
+// keys keeping their ascii code
+const (
+	SysTab       = '\t'
+	SysEnter     = '\r'
+	SysEsc       = 0x1b
+	SysBackspace = 0x7f
+)
+
+// Mod key modifiers, bits follow the xterm modifier parameter minus one
+type Mod uint8
+
+const (
+	ModShift Mod = 1 << iota
+	ModAlt
+	ModCtrl
+)
+
+// Key a key pressed on the terminal
+type Key struct {
+	// Code the ascii code of a single byte key, SysRune for a non-ascii character,
+	// or one of SysUp, ..., SysF12
+	Code int
+	// Rune the printable character, 0 for control and special keys
+	Rune rune
+	// Mod modifiers held with the key
+	Mod Mod
+	// Raw the undecoded input when Code is SysParse
+	Raw string
+	// Mouse the mouse report when Code is SysMouse
+	Mouse MouseEvent
+}
+
+var keyNames = map[int]string{
+	SysUp:        "Up",
+	SysDown:      "Down",
+	SysLeft:      "Left",
+	SysRight:     "Right",
+	SysHome:      "Home",
+	SysEnd:       "End",
+	SysPgUp:      "PgUp",
+	SysPgDn:      "PgDn",
+	SysInsert:    "Insert",
+	SysDelete:    "Delete",
+	SysF1:        "F1",
+	SysF2:        "F2",
+	SysF3:        "F3",
+	SysF4:        "F4",
+	SysF5:        "F5",
+	SysF6:        "F6",
+	SysF7:        "F7",
+	SysF8:        "F8",
+	SysF9:        "F9",
+	SysF10:       "F10",
+	SysF11:       "F11",
+	SysF12:       "F12",
+	SysTab:       "Tab",
+	SysEnter:     "Enter",
+	SysEsc:       "Esc",
+	SysBackspace: "Backspace",
+	' ':          "Space",
+	SysFocusIn:   "FocusIn",
+	SysFocusOut:  "FocusOut",
+}
+
+// String key name such as `a`, `Up`, `Ctrl+C` or `Shift+F5`
+func (k Key) String() string {
+	var b strings.Builder
+	if k.Mod&ModCtrl != 0 {
+		b.WriteString("Ctrl+")
+	}
+	if k.Mod&ModAlt != 0 {
+		b.WriteString("Alt+")
+	}
+	if k.Mod&ModShift != 0 {
+		b.WriteString("Shift+")
+	}
+
+	switch name, ok := keyNames[k.Code]; {
+	case ok:
+		b.WriteString(name)
+	case k.Code == SysParse:
+		b.WriteString(strings.ReplaceAll(k.Raw, "\x1b", "ESC"))
+	case k.Rune != 0:
+		b.WriteRune(k.Rune)
+	case k.Code < ' ': // ctrl + letter
+		b.WriteByte(byte(k.Code) + '@')
+	}
+	return b.String()
+}
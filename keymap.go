@@ -0,0 +1,210 @@
+package game
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Action something the player does, bound to keys by a Keymap. The name is the key in keys.toml.
+type Action string
+
+const (
+	ActionUp        Action = "up"
+	ActionDown      Action = "down"
+	ActionLeft      Action = "left"
+	ActionRight     Action = "right"
+	ActionRotateCW  Action = "rotate_cw"
+	ActionRotateCCW Action = "rotate_ccw"
+	ActionDrop      Action = "drop"
+	ActionHold      Action = "hold"
+	ActionReset     Action = "reset"
+	ActionPause     Action = "pause" // pauses and resumes, the game can pause only if it binds the action
+	ActionMenu      Action = "menu"  // opens the game menu, Esc if not bound
+	ActionQuit      Action = "quit"
+)
+
+// Binding keys doing one action, and the help shown to the player
+type Binding struct {
+	Action Action
+	Keys   []Key
+	Help   string
+}
+
+// KeyRune the key of the printable rune `r`
+func KeyRune(r rune) Key {
+	if r < 0x80 {
+		return Key{Code: int(r), Rune: r}
+	}
+	return Key{Code: SysRune, Rune: r}
+}
+
+// KeyCode the key of code `c`, e.g. SysUp or SysEnter
+func KeyCode(c int) Key {
+	return Key{Code: c}
+}
+
+// ParseKey parse a key name as Key.String gives: `a`, `Up`, `Space`, `Ctrl+C` or `Shift+F5`, case insensitive
+// except for the single characters
+func ParseKey(s string) (Key, error) {
+	var mod Mod
+	name := s
+	for {
+		i := strings.IndexByte(name, '+')
+		if i <= 0 || i == len(name)-1 {
+			break
+		}
+		switch strings.ToLower(name[:i]) {
+		case "ctrl":
+			mod |= ModCtrl
+		case "alt":
+			mod |= ModAlt
+		case "shift":
+			mod |= ModShift
+		default:
+			return Key{}, fmt.Errorf("unknown modifier in key %q", s)
+		}
+		name = name[i+1:]
+	}
+
+	if r, n := utf8.DecodeRuneInString(name); n == len(name) && r != utf8.RuneError {
+		if mod&ModCtrl != 0 && (r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z') { // as the terminal sends it
+			return Key{Code: int(r&^0x20) - '@', Mod: mod}, nil
+		}
+		k := KeyRune(r)
+		k.Mod = mod
+		return k, nil
+	}
+	for code, n := range keyNames {
+		if strings.EqualFold(n, name) {
+			k := Key{Code: code}
+			if code == ' ' { // the space bar sends the character, as KeyRune
+				k = KeyRune(' ')
+			}
+			k.Mod = mod
+			return k, nil
+		}
+	}
+	return Key{}, fmt.Errorf("unknown key %q", s)
+}
+
+// match whether `k` is the key `o`, characters in any case
+func (k Key) match(o Key) bool {
+	if k.Rune != 0 && o.Rune != 0 { // shift is in the case of the character
+		return unicode.ToLower(k.Rune) == unicode.ToLower(o.Rune) && k.Mod&^ModShift == o.Mod&^ModShift
+	}
+	return k.Code == o.Code && k.Rune == o.Rune && k.Mod == o.Mod
+}
+
+// Keymap the bindings of a game, in the order of the help
+type Keymap []Binding
+
+// Action the action bound to key `k`, empty if none
+func (km Keymap) Action(k Key) Action {
+	for _, b := range km {
+		for _, o := range b.Keys {
+			if o.match(k) {
+				return b.Action
+			}
+		}
+	}
+	return ""
+}
+
+// Keys the keys bound to action `a`
+func (km Keymap) Keys(a Action) []Key {
+	if b := km.binding(a); b != nil {
+		return b.Keys
+	}
+	return nil
+}
+
+func (km Keymap) binding(a Action) *Binding {
+	for i := range km {
+		if km[i].Action == a {
+			return &km[i]
+		}
+	}
+	return nil
+}
+
+// ParseKeymap parse the bindings in TOML over `defaults`, one action per line with a key or a list of keys:
+//
+//	# vim keys
+//	left = ["h", "Left"]
+//	rotate_cw = "x"
+//	quit = []
+//
+// A key bound again is taken from the action of `defaults` it was bound to.
+func ParseKeymap(data []byte, defaults Keymap) (Keymap, error) {
+	entries, err := parseTOML(string(data))
+	if err != nil {
+		return nil, err
+	}
+
+	km := make(Keymap, len(defaults))
+	copy(km, defaults)
+	for _, e := range entries {
+		b := km.binding(Action(e.key))
+		if b == nil {
+			return nil, fmt.Errorf("line %d: unknown action %q", e.line, e.key)
+		}
+
+		keys := make([]Key, len(e.values))
+		for i, v := range e.values {
+			if keys[i], err = ParseKey(v); err != nil {
+				return nil, fmt.Errorf("line %d: %v", e.line, err)
+			}
+			km.unbind(keys[i])
+		}
+		b.Keys = keys
+	}
+	return km, nil
+}
+
+// unbind take key `k` from all the bindings
+func (km Keymap) unbind(k Key) {
+	for i := range km {
+		keys := make([]Key, 0, len(km[i].Keys))
+		for _, o := range km[i].Keys {
+			if !o.match(k) {
+				keys = append(keys, o)
+			}
+		}
+		km[i].Keys = keys
+	}
+}
+
+// LoadKeymap load the bindings from the TOML file `path` over `defaults`, see ParseKeymap.
+// A missing file gives the defaults.
+func LoadKeymap(path string, defaults Keymap) (Keymap, error) {
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return defaults, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	km, err := ParseKeymap(data, defaults)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v", path, err)
+	}
+	return km, nil
+}
+
+// KeymapPath the file of the bindings of game `name`: $XDG_CONFIG_HOME/<name>/keys.toml,
+// ~/.config/<name>/keys.toml by default
+func KeymapPath(name string) string {
+	dir := os.Getenv("XDG_CONFIG_HOME")
+	if dir == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return ""
+		}
+		dir = filepath.Join(home, ".config")
+	}
+	return filepath.Join(dir, name, "keys.toml")
+}
@@ -0,0 +1,125 @@
+package game
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseKey(t *testing.T) {
+	tests := []struct {
+		in   string
+		want Key
+	}{
+		{"a", Key{Code: 'a', Rune: 'a'}},
+		{"+", Key{Code: '+', Rune: '+'}},
+		{"é", Key{Code: SysRune, Rune: 'é'}},
+		{"up", Key{Code: SysUp}},
+		{"Space", Key{Code: ' ', Rune: ' '}},
+		{"Esc", Key{Code: SysEsc}},
+		{"Ctrl+c", Key{Code: 3, Mod: ModCtrl}},
+		{"Shift+F5", Key{Code: SysF5, Mod: ModShift}},
+		{"alt+Ctrl+Left", Key{Code: SysLeft, Mod: ModAlt | ModCtrl}},
+	}
+	for _, tt := range tests {
+		got, err := ParseKey(tt.in)
+		if err != nil || got != tt.want {
+			t.Errorf("ParseKey(%q) = %+v, %v, want %+v", tt.in, got, err, tt.want)
+		}
+	}
+
+	for _, in := range []string{"", "Hyper+a", "abc", "F13"} {
+		if _, err := ParseKey(in); err == nil {
+			t.Errorf("ParseKey(%q) no error", in)
+		}
+	}
+}
+
+var testKeys = Keymap{
+	{Action: ActionQuit, Keys: []Key{KeyRune('q')}, Help: "exit"},
+	{Action: ActionLeft, Keys: []Key{KeyRune('a'), KeyCode(SysLeft)}, Help: "left"},
+	{Action: ActionDown, Keys: []Key{KeyRune('s'), KeyCode(SysDown)}, Help: "down"},
+	{Action: ActionRotateCW, Keys: []Key{KeyRune('w'), KeyCode(SysUp)}, Help: "rotate"},
+}
+
+func TestKeymapAction(t *testing.T) {
+	tests := []struct {
+		k    Key
+		want Action
+	}{
+		{Key{Code: 'a', Rune: 'a'}, ActionLeft},
+		{Key{Code: 'A', Rune: 'A'}, ActionLeft},
+		{Key{Code: SysLeft}, ActionLeft},
+		{Key{Code: SysLeft, Mod: ModCtrl}, ""},
+		{Key{Code: 'a', Rune: 'a', Mod: ModAlt}, ""},
+		{Key{Code: 'x', Rune: 'x'}, ""},
+	}
+	for _, tt := range tests {
+		if got := testKeys.Action(tt.k); got != tt.want {
+			t.Errorf("Action(%v) = %q, want %q", tt.k, got, tt.want)
+		}
+	}
+}
+
+func TestParseKeymap(t *testing.T) {
+	km, err := ParseKeymap([]byte(`
+# vim keys
+left = ["h", 'Left',]   # and the arrow
+"down" = "j"
+rotate_cw = ["s"]
+quit = []
+`), testKeys)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := map[Action][]Key{
+		ActionQuit:     {},
+		ActionLeft:     {KeyRune('h'), KeyCode(SysLeft)},
+		ActionDown:     {KeyRune('j')},
+		ActionRotateCW: {KeyRune('s')},
+	}
+	for a, keys := range want {
+		if got := km.Keys(a); !reflect.DeepEqual(got, keys) {
+			t.Errorf("keys of %s = %v, want %v", a, got, keys)
+		}
+	}
+	if got := testKeys.Keys(ActionQuit); len(got) != 1 {
+		t.Errorf("defaults changed: %v", got)
+	}
+
+	h := KeyHelp{Bindings: km}
+	if got := strings.Join(h.lines(), "\n"); got != "h/Left -> left\nj      -> down\ns      -> rotate" {
+		t.Errorf("help:\n%s", got)
+	}
+
+	// the space bar as the decoder sends it, taken from the default binding
+	spaced, err := ParseKeymap([]byte(`left = "Space"`), append(Keymap{
+		{Action: ActionPause, Keys: []Key{KeyRune(' ')}, Help: "pause"},
+	}, testKeys...))
+	if err != nil {
+		t.Fatal(err)
+	}
+	space := new(Decoder).Feed([]byte(" "))[0]
+	if got := spaced.Action(space); got != ActionLeft {
+		t.Errorf("Action(%+v) = %q, want %q", space, got, ActionLeft)
+	}
+	if got := spaced.Keys(ActionPause); len(got) != 0 {
+		t.Errorf("keys of pause = %v, want none", got)
+	}
+
+	for _, bad := range []string{
+		"jump = \"x\"",
+		"left = \"Hyper+x\"",
+		"left = h",
+		"left = [\"h\" \"j\"]",
+		"left = \"h\" x",
+		"[keys]",
+		"left = \"h\"\nleft = \"j\"",
+		"left = \"h",
+	} {
+		if _, err := ParseKeymap([]byte(bad), testKeys); err == nil {
+			t.Errorf("ParseKeymap(%q) no error", bad)
+		}
+	}
+}
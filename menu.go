@@ -0,0 +1,171 @@
+package game
+
+import (
+	"strings"
+	"unicode"
+)
+
+// Modal a widget shown over the game by Runner.ShowModal, taking the keys until it closes
+type Modal interface {
+	Widget
+	// Update handle key `k`, return true to close the modal
+	Update(k Key) (done bool)
+}
+
+// MenuItem an entry of a Menu
+type MenuItem struct {
+	Label  string
+	Action func() // called when the item is chosen, nil to just close the menu
+	Stay   bool   // keep the menu open after the action, e.g. to toggle a setting
+}
+
+// Menu a list of items, chosen with the arrow keys (or the keys of ActionUp and ActionDown) and Enter (or Space)
+type Menu struct {
+	Title    string
+	Items    []MenuItem
+	Selected int
+	Cancel   func() // called on Esc or the keys of ActionMenu, nil if the menu can not be dismissed
+	Style    Style  // style of the border and the items
+	Keys     Keymap // the bindings of the game, nil for the arrow keys only
+}
+
+// Update move the selection or choose the selected item
+func (m *Menu) Update(k Key) bool {
+	if len(m.Items) == 0 {
+		return true
+	}
+
+	switch a := m.Keys.Action(k); {
+	case k.Code == SysUp || a == ActionUp:
+		m.Selected = (m.Selected + len(m.Items) - 1) % len(m.Items)
+	case k.Code == SysDown || k.Code == SysTab || a == ActionDown:
+		m.Selected = (m.Selected + 1) % len(m.Items)
+	case k.Code == SysEnter || k.Code == ' ':
+		it := m.Items[m.Selected]
+		if it.Action != nil {
+			it.Action()
+		}
+		return !it.Stay
+	case k.Code == SysEsc || a == ActionMenu:
+		if m.Cancel != nil {
+			m.Cancel()
+			return true
+		}
+	}
+	return false
+}
+
+func (m *Menu) widget() Widget {
+	width := 0
+	for _, it := range m.Items {
+		if w := StringWidth(it.Label); w > width {
+			width = w
+		}
+	}
+
+	items := make([]Widget, len(m.Items))
+	for i, it := range m.Items {
+		st := m.Style
+		if i == m.Selected {
+			st = st.Reverse()
+		}
+		items[i] = Label{Text: " " + PadRight(it.Label, width) + " ", Style: st}
+	}
+	return Box{Title: m.Title, Style: m.Style, Padding: 1, Child: VBox{Children: items}}
+}
+
+// Size the items in a box
+func (m *Menu) Size() (rows, cols int) {
+	return m.widget().Size()
+}
+
+// Draw draw the items in a box, the selected one reversed
+func (m *Menu) Draw(s *Screen, p Point, rows, cols int) {
+	m.widget().Draw(s, p, rows, cols)
+}
+
+// Button a button of a Dialog
+type Button struct {
+	Label  string
+	Key    rune   // pressing it chooses the button at once, 0 for none
+	Action func() // called when the button is chosen, nil to just close the dialog
+}
+
+// Dialog a message with buttons below, chosen with the arrow keys (or Tab) and Enter, or their keys
+type Dialog struct {
+	Title    string
+	Text     string // lines split by '\n'
+	Buttons  []Button
+	Selected int
+	Style    Style // style of the border and the buttons
+}
+
+// Update move the selection or choose a button
+func (d *Dialog) Update(k Key) bool {
+	if len(d.Buttons) == 0 {
+		return k.Code == SysEnter || k.Code == SysEsc
+	}
+
+	switch k.Code {
+	case SysLeft:
+		d.Selected = (d.Selected + len(d.Buttons) - 1) % len(d.Buttons)
+	case SysRight, SysTab:
+		d.Selected = (d.Selected + 1) % len(d.Buttons)
+	case SysEnter:
+		return d.choose(d.Selected)
+	default:
+		for i, b := range d.Buttons {
+			if b.Key != 0 && k.Rune != 0 && unicode.ToLower(k.Rune) == unicode.ToLower(b.Key) {
+				return d.choose(i)
+			}
+		}
+	}
+	return false
+}
+
+func (d *Dialog) choose(i int) bool {
+	if a := d.Buttons[i].Action; a != nil {
+		a()
+	}
+	return true
+}
+
+func (d *Dialog) widget() Widget {
+	var lines []Widget
+	for _, l := range strings.Split(d.Text, "\n") {
+		lines = append(lines, Label{Text: l})
+	}
+
+	if len(d.Buttons) > 0 {
+		buttons := make([]Widget, len(d.Buttons))
+		for i, b := range d.Buttons {
+			st := d.Style
+			if i == d.Selected {
+				st = st.Reverse()
+			}
+			buttons[i] = Label{Text: "[ " + b.Label + " ]", Style: st}
+		}
+		lines = append(lines, Spacer{Rows: 1}, HBox{Gap: 2, Children: buttons})
+	}
+	return Box{Title: d.Title, Style: d.Style, Padding: 1, Child: VBox{Children: lines}}
+}
+
+// Size the text and the buttons in a box
+func (d *Dialog) Size() (rows, cols int) {
+	return d.widget().Size()
+}
+
+// Draw draw the text and the buttons in a box, the selected button reversed
+func (d *Dialog) Draw(s *Screen, p Point, rows, cols int) {
+	d.widget().Draw(s, p, rows, cols)
+}
+
+// drawModal draw `m` in the middle of the screen over a blank area
+func drawModal(s *Screen, m Widget) {
+	rows, cols := m.Size()
+	s.SetOrigin(s.Center(rows, cols))
+	for i := 0; i < rows; i++ {
+		s.Fill(Point{X: i}, cols, ' ', StyleDefault)
+	}
+	m.Draw(s, Point{}, rows, cols)
+}
@@ -0,0 +1,128 @@
+//go:build linux || darwin
+
+package game
+
+import "strings"
+
+// EnableMenu offer the game menu of New Game, Resume, Settings and Quit: it is shown at the start
+// and on Esc or the keys of ActionMenu, and New Game calls `newGame` to reset the game. GameOver then asks to play again.
+func (r *Runner) EnableMenu(newGame func()) {
+	r.newGame = newGame
+}
+
+// GameOver ask the player to play again when the menu is enabled, else end the game with outcome `o`
+func (r *Runner) GameOver(o Outcome) {
+	if r.newGame == nil {
+		r.End(o)
+		return
+	}
+
+	text := "Game over — play again? (y/n)"
+	if o == OutcomeWin {
+		text = "You win — play again? (y/n)"
+	}
+	r.ShowModal(&Dialog{
+		Text:  text,
+		Style: r.Theme().Style(RoleBorder),
+		Buttons: []Button{
+			{Label: "Yes", Key: 'y', Action: r.restart},
+			{Label: "No", Key: 'n', Action: func() { r.End(o) }},
+		},
+	})
+}
+
+// menuKey let the menus take key `k`: the modal on top, or the key of ActionMenu opening the game menu
+func (r *Runner) menuKey(k Key) bool {
+	if m := r.Modal(); m != nil {
+		if m.Update(k) {
+			r.CloseModal(m)
+		}
+		return true
+	}
+
+	if r.newGame != nil && r.isMenuKey(k) {
+		r.ShowModal(r.gameMenu(false))
+		return true
+	}
+	return false
+}
+
+// gameMenu the game menu, without Resume at the start as the game is ready to play
+func (r *Runner) gameMenu(start bool) *Menu {
+	m := &Menu{Title: "Menu", Style: r.Theme().Style(RoleBorder), Keys: r.keymap}
+	if start {
+		m.Items = append(m.Items, MenuItem{Label: "New Game"})
+	} else {
+		m.Items = append(m.Items, MenuItem{Label: "New Game", Action: r.restart}, MenuItem{Label: "Resume", Action: r.resume})
+		m.Selected = 1
+		m.Cancel = r.resume
+	}
+	m.Items = append(m.Items,
+		MenuItem{Label: "Settings", Action: func() { r.ShowModal(r.settingsMenu(start)) }},
+		MenuItem{Label: "Quit", Action: r.Quit},
+	)
+	return m
+}
+
+// settingsMenu the settings, going back to the game menu
+func (r *Runner) settingsMenu(start bool) *Menu {
+	back := func() { r.ShowModal(r.gameMenu(start)) }
+	m := &Menu{Title: "Settings", Style: r.Theme().Style(RoleBorder), Cancel: back, Keys: r.keymap}
+	m.Items = []MenuItem{
+		{Label: "Colors: " + r.output().ColorProfile().String(), Stay: true, Action: func() {
+			p := (r.output().ColorProfile() + 1) % (ColorsRGB + 1)
+			r.colors = &p
+			r.output().SetColorProfile(p)
+			if r.screen != nil {
+				r.screen.Invalidate()
+			}
+			m.Items[0].Label = "Colors: " + p.String()
+		}},
+		{Label: "Back", Action: back},
+	}
+	return m
+}
+
+// isMenuKey whether `k` opens the game menu, Esc unless the keymap binds ActionMenu
+func (r *Runner) isMenuKey(k Key) bool {
+	if r.keymap.binding(ActionMenu) == nil {
+		return k.Code == SysEsc
+	}
+	return r.keymap.Action(k) == ActionMenu
+}
+
+// pausable whether the game can pause, i.e. binds ActionPause
+func (r *Runner) pausable() bool {
+	return len(r.keymap.Keys(ActionPause)) > 0
+}
+
+// pauseKey let the pause take key `k`: the keys of ActionPause pause and resume, the others are dropped while paused
+func (r *Runner) pauseKey(k Key) bool {
+	if r.pausable() && r.keymap.Action(k) == ActionPause {
+		r.paused = !r.paused
+		return true
+	}
+	return r.paused
+}
+
+func (r *Runner) resume() {
+	r.paused = false
+}
+
+// restart start a new game, not paused
+func (r *Runner) restart() {
+	r.paused = false
+	r.newGame()
+}
+
+// pausedBox the overlay of the paused game
+func (r *Runner) pausedBox() Widget {
+	names := make([]string, 0, 2)
+	for _, k := range r.keymap.Keys(ActionPause) {
+		names = append(names, k.String())
+	}
+	return Box{Style: r.Theme().Style(RoleBorder), Padding: 2, Child: VBox{Gap: 1, Children: []Widget{
+		Label{Text: "PAUSED", Style: r.Theme().Style(RoleTitle)},
+		Label{Text: strings.Join(names, "/") + " to resume"},
+	}}}
+}
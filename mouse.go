@@ -0,0 +1,77 @@
+package game
+
+// MouseButton the button of a mouse event
+type MouseButton uint8
+
+const (
+	MouseNone MouseButton = iota
+	MouseLeft
+	MouseMiddle
+	MouseRight
+	MouseWheelUp
+	MouseWheelDown
+	MouseWheelLeft
+	MouseWheelRight
+)
+
+// MouseAction what the mouse did
+type MouseAction uint8
+
+const (
+	MousePress MouseAction = iota
+	MouseRelease
+	MouseDrag // move with a button held
+	MouseMove // move without any button, only reported with all motion tracking
+)
+
+// MouseEvent a mouse report, Point(x, y) starts with (0, 0) from left-top (x => row, y => col) like DrawAt
+type MouseEvent struct {
+	Point
+	Button MouseButton
+	Action MouseAction
+	Mod    Mod
+}
+
+// mouse report button bits
+const (
+	mouseButtonMask = 0x03
+	mouseShift      = 0x04
+	mouseAlt        = 0x08
+	mouseCtrl       = 0x10
+	mouseMotion     = 0x20
+	mouseWheel      = 0x40
+)
+
+// decodeMouse decode the button code `b` of a report at 1-based (col, row)
+func decodeMouse(b, col, row int, release bool) MouseEvent {
+	e := MouseEvent{Point: Point{X: row - 1, Y: col - 1}}
+
+	if b&mouseShift != 0 {
+		e.Mod |= ModShift
+	}
+	if b&mouseAlt != 0 {
+		e.Mod |= ModAlt
+	}
+	if b&mouseCtrl != 0 {
+		e.Mod |= ModCtrl
+	}
+
+	if b&mouseWheel != 0 {
+		e.Button = MouseWheelUp + MouseButton(b&mouseButtonMask)
+		return e
+	}
+
+	if n := b & mouseButtonMask; n < 3 {
+		e.Button = MouseLeft + MouseButton(n)
+	}
+
+	switch {
+	case release || (e.Button == MouseNone && b&mouseMotion == 0): // x10 reports release as button 3
+		e.Action = MouseRelease
+	case b&mouseMotion != 0 && e.Button == MouseNone:
+		e.Action = MouseMove
+	case b&mouseMotion != 0:
+		e.Action = MouseDrag
+	}
+	return e
+}
@@ -4,53 +4,239 @@ package game
 
 import (
 	"fmt"
+	"io"
+	"os"
 	"strconv"
 )
 
+// Output draws to an io.Writer, e.g. stdout, a file, a socket or a buffer
+type Output struct {
+	w       io.Writer
+	profile ColorProfile
+}
+
+// NewOutput output drawing to `w`, colors are sent as they are until SetColorProfile
+func NewOutput(w io.Writer) *Output {
+	return &Output{w: w, profile: ColorsRGB}
+}
+
+// std the output of the package level drawing functions, with the colors of the terminal
+var std = &Output{w: os.Stdout, profile: DetectColorProfile()}
+
+// SetColorProfile convert the colors to what profile `p` can show, e.g. DetectColorProfile()
+func (o *Output) SetColorProfile(p ColorProfile) {
+	o.profile = p
+}
+
+// ColorProfile the colors the output can show
+func (o *Output) ColorProfile() ColorProfile {
+	return o.profile
+}
+
+// SetOutput set the writer of the package level drawing functions, stdout by default
+func SetOutput(w io.Writer) {
+	std.w = w
+}
+
+// StdOutput the output of the package level drawing functions
+func StdOutput() *Output {
+	return std
+}
+
+// Write write `p` as it is
+func (o *Output) Write(p []byte) (int, error) {
+	return o.w.Write(p)
+}
+
+// Clear screen
+func (o *Output) Clear() {
+	fmt.Fprint(o.w, ClearPrev+ClearAll)
+}
+
+// DrawAt draw string `s` at Point `p`. Point(x, y) starts with (0, 0) from left-top (x => row, y => col)
+func (o *Output) DrawAt(p Point, s string) {
+	fmt.Fprint(o.w, cursorPos(p.X+1, p.Y+1)+s)
+}
+
+// Draw string `s` at current Position
+func (o *Output) Draw(s string) {
+	fmt.Fprint(o.w, s)
+}
+
+// DrawLine Draw string `s` at current Position with new line
+func (o *Output) DrawLine(s string) {
+	fmt.Fprintln(o.w, s)
+}
+
+// DrawLineAt Draw string `s` at Point `p` with new line
+func (o *Output) DrawLineAt(p Point, s string) {
+	fmt.Fprintln(o.w, cursorPos(p.X+1, p.Y+1)+s)
+}
+
+// DrawSgr Draw content `s` with Sgr definitions
+func (o *Output) DrawSgr(content string, sgr ...string) {
+	fmt.Fprintf(o.w, "%s%s%s", SgrSet(sgr...), content, SgrReset())
+}
+
+// DrawStyle draw content `s` with Style `st`
+func (o *Output) DrawStyle(s string, st Style) {
+	fmt.Fprintf(o.w, "%s%s%s", st.Convert(o.profile).sgr(), s, SgrReset())
+}
+
+// DrawColor8 draw `s` with color(ColorType, Color8)
+func (o *Output) DrawColor8(t ColorType, c Color8, s string) {
+	if c < ColorMax {
+		o.drawColor(t, c.Color(), s)
+	}
+}
+
+// DrawColor256 draw `s` with color256(ColorType, uint8)
+func (o *Output) DrawColor256(t ColorType, c uint8, s string) {
+	o.drawColor(t, Color256(c), s)
+}
+
+// DrawColorRGB draw `s` with colorRgb(ColorType, RGB)
+func (o *Output) DrawColorRGB(t ColorType, c RGB, s string) {
+	o.drawColor(t, c.Color(), s)
+}
+
+// drawColor draw `s` with color `c` converted to the profile, the bright types take the bright color
+func (o *Output) drawColor(t ColorType, c Color, s string) {
+	if t > BrightBackground {
+		return
+	}
+	if t >= BrightForeground {
+		t -= BrightForeground
+		if c.Mode == ColorMode8 && c.Value < 8 {
+			c.Value += 8
+		}
+	}
+
+	if sgr := c.Convert(o.profile).sgr(t); sgr != "" {
+		o.DrawSgr(s, sgr)
+	} else {
+		o.DrawSgr(s)
+	}
+}
+
+// Cursor move cursor to Point(p) starts with (0,0) from left-top
+func (o *Output) Cursor(p Point) {
+	fmt.Fprint(o.w, cursorPos(p.X+1, p.Y+1))
+}
+
+// CursorUp move cursor up
+func (o *Output) CursorUp(n int) {
+	fmt.Fprintf(o.w, "%s%dA", _CSI, n)
+}
+
+// CursorDown move cursor down
+func (o *Output) CursorDown(n int) {
+	fmt.Fprintf(o.w, "%s%dB", _CSI, n)
+}
+
+// CursorForward move cursor forward
+func (o *Output) CursorForward(n int) {
+	fmt.Fprintf(o.w, "%s%dC", _CSI, n)
+}
+
+// CursorBack move cursor back
+func (o *Output) CursorBack(n int) {
+	fmt.Fprintf(o.w, "%s%dD", _CSI, n)
+}
+
+// CursorNextLine move cursor next line
+func (o *Output) CursorNextLine(n int) {
+	fmt.Fprintf(o.w, "%s%dE", _CSI, n)
+}
+
+// CursorPrevLine move cursor prev line
+func (o *Output) CursorPrevLine(n int) {
+	fmt.Fprintf(o.w, "%s%dF", _CSI, n)
+}
+
+// CursorPos move cursor to (x, y) starts with (1, 1)
+func (o *Output) CursorPos(x, y int) {
+	fmt.Fprint(o.w, cursorPos(x, y))
+}
+
+// CursorSave save current cursor position
+func (o *Output) CursorSave() {
+	fmt.Fprint(o.w, SCP)
+}
+
+// CursorRestore restore saved position
+func (o *Output) CursorRestore() {
+	fmt.Fprint(o.w, RCP)
+}
+
+// HideCursor hide the cursor
+func (o *Output) HideCursor() {
+	fmt.Fprint(o.w, CursorHide)
+}
+
+// ShowCursor show the cursor
+func (o *Output) ShowCursor() {
+	fmt.Fprint(o.w, CursorShow)
+}
+
+// EnterAltScreen switch to the alternate screen, the main screen and its scrollback are kept aside
+func (o *Output) EnterAltScreen() {
+	fmt.Fprint(o.w, AltScreenOn)
+}
+
+// ExitAltScreen switch back to the main screen
+func (o *Output) ExitAltScreen() {
+	fmt.Fprint(o.w, AltScreenOff)
+}
+
 // Clear screen
 func Clear() {
-	fmt.Print(ClearPrev)
-	fmt.Print(ClearAll)
+	std.Clear()
 }
 
 // DrawAt draw string `s` at Point `p`. Point(x, y) starts with (0, 0) from left-top (x => row, y => col)
 func DrawAt(p Point, s string) {
-	fmt.Print(cursorPos(p.X+1, p.Y+1) + s)
+	std.DrawAt(p, s)
 }
 
 // Draw string `s` at current Position
 func Draw(s string) {
-	fmt.Print(s)
+	std.Draw(s)
 }
 
 // DrawLine Draw string `s` at current Position with new line
 func DrawLine(s string) {
-	fmt.Println(s)
+	std.DrawLine(s)
 }
 
 // DrawLineAt Draw string `s` at Point `p` with new line
 func DrawLineAt(p Point, s string) {
-	fmt.Println(cursorPos(p.X+1, p.Y+1) + s)
+	std.DrawLineAt(p, s)
 }
 
 // DrawSgr Draw content `s` with Sgr definitions
 func DrawSgr(content string, sgr ...string) {
-	fmt.Printf("%s%s%s", SgrSet(sgr...), content, SgrReset())
+	std.DrawSgr(content, sgr...)
+}
+
+// DrawStyle draw content `s` with Style `st`
+func DrawStyle(s string, st Style) {
+	std.DrawStyle(s, st)
 }
 
 // DrawColor8 draw `s` with color(ColorType, Color8)
 func DrawColor8(t ColorType, c Color8, s string) {
-	DrawSgr(s, SgrColor(t, c))
+	std.DrawColor8(t, c, s)
 }
 
 // DrawColor256 draw `s` with color256(ColorType, uint8)
 func DrawColor256(t ColorType, c uint8, s string) {
-	DrawSgr(s, SgrColor8bit(t, c))
+	std.DrawColor256(t, c, s)
 }
 
 // DrawColorRGB draw `s` with colorRgb(ColorType, RGB)
 func DrawColorRGB(t ColorType, c RGB, s string) {
-	DrawSgr(s, SgrColorRGB(t, c))
+	std.DrawColorRGB(t, c, s)
 }
 
 // csi 转义序列定义， 参考：
@@ -67,56 +253,80 @@ const (
 	ClearLineFull = _CSI + "2K"
 	SCP           = _CSI + "s" // 保存光标位置
 	RCP           = _CSI + "u" // 恢复光标位置
+	CursorHide    = _CSI + "?25l"
+	CursorShow    = _CSI + "?25h"
+	AltScreenOn   = _CSI + "?1049h" // 备用屏幕，退出后恢复主屏幕和滚动历史
+	AltScreenOff  = _CSI + "?1049l"
 )
 
 // Cursor move cursor to Point(p) starts with (0,0) from left-top
 func Cursor(p Point) {
-	fmt.Print(cursorPos(p.X+1, p.Y+1))
+	std.Cursor(p)
 }
 
 // CursorUp move cursor up
 func CursorUp(n int) {
-	fmt.Printf("%s%dA", _CSI, n)
+	std.CursorUp(n)
 }
 
 // CursorDown move cursor down
 func CursorDown(n int) {
-	fmt.Printf("%s%dB", _CSI, n)
+	std.CursorDown(n)
 }
 
 // CursorForward move cursor forward
 func CursorForward(n int) {
-	fmt.Printf("%s%dC", _CSI, n)
+	std.CursorForward(n)
 }
 
 // CursorBack move cursor back
 func CursorBack(n int) {
-	fmt.Printf("%s%dD", _CSI, n)
+	std.CursorBack(n)
 }
 
 // CursorNextLine move cursor next line
 func CursorNextLine(n int) {
-	fmt.Printf("%s%dE", _CSI, n)
+	std.CursorNextLine(n)
 }
 
 // CursorPrevLine move cursor prev line
 func CursorPrevLine(n int) {
-	fmt.Printf("%s%dF", _CSI, n)
+	std.CursorPrevLine(n)
 }
 
 // CursorPos move cursor to (x, y) starts with (1, 1)
 func CursorPos(x, y int) {
-	fmt.Print(cursorPos(x, y))
+	std.CursorPos(x, y)
 }
 
 // CursorSave save current cursor position
 func CursorSave() {
-	fmt.Print(SCP)
+	std.CursorSave()
 }
 
 // CursorRestore restore saved position
 func CursorRestore() {
-	fmt.Print(RCP)
+	std.CursorRestore()
+}
+
+// HideCursor hide the cursor
+func HideCursor() {
+	std.HideCursor()
+}
+
+// ShowCursor show the cursor
+func ShowCursor() {
+	std.ShowCursor()
+}
+
+// EnterAltScreen switch to the alternate screen, the main screen and its scrollback are kept aside
+func EnterAltScreen() {
+	std.EnterAltScreen()
+}
+
+// ExitAltScreen switch back to the main screen
+func ExitAltScreen() {
+	std.ExitAltScreen()
 }
 
 func cursorPos(x, y int) string {
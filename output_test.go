@@ -1,26 +1,67 @@
 package game
 
 import (
+	"bytes"
 	"testing"
 )
 
 func TestCursor(t *testing.T) {
-	Clear()
-	Cursor(Point{})
-	DrawAt(Point{0, 3}, "111")
-	Draw("123")
-	DrawLine("456")
-	DrawColor8(Foreground, ColorRed, "123")
-	DrawColor256(Foreground, 0x1f, "123")
-	DrawColorRGB(Foreground, RGB{0xcc, 0xcc, 0xcc}, "123")
-	DrawSgr("hello world")
+	var buf bytes.Buffer
+	o := NewOutput(&buf)
 
-	DrawLine("1")
-	Draw("1234567890")
-	CursorUp(1)
-	Draw(ClearLinePrev)
-	CursorDown(1)
-	CursorPos(1, 2)
-	Draw(ClearLineNext)
-	DrawSgr("hello world", SgrBold, SgrFaint, SgrItalic, SgrUnderLine, SgrReverse)
+	tests := []struct {
+		draw func()
+		want string
+	}{
+		{o.Clear, "\x1b[1J\x1b[2J"},
+		{func() { o.Cursor(Point{}) }, "\x1b[1;1H"},
+		{func() { o.DrawAt(Point{X: 0, Y: 3}, "111") }, "\x1b[1;4H111"},
+		{func() { o.Draw("123") }, "123"},
+		{func() { o.DrawLine("456") }, "456\n"},
+		{func() { o.DrawLineAt(Point{X: 2, Y: 1}, "7") }, "\x1b[3;2H7\n"},
+		{func() { o.DrawColor8(Foreground, ColorRed, "123") }, "\x1b[31m123\x1b[m"},
+		{func() { o.DrawColor256(Foreground, 0x1f, "123") }, "\x1b[38;5;31m123\x1b[m"},
+		{func() { o.DrawColorRGB(Background, RGB{0xcc, 0xcc, 0xcc}, "123") }, "\x1b[48;2;204;204;204m123\x1b[m"},
+		{func() { o.DrawSgr("hello world") }, "hello world\x1b[m"},
+		{func() { o.DrawStyle("hi", Style{Fg: ColorRed.Color(), Attr: AttrBold}) }, "\x1b[0;1;31mhi\x1b[m"},
+		{func() { o.CursorUp(1) }, "\x1b[1A"},
+		{func() { o.CursorDown(2) }, "\x1b[2B"},
+		{func() { o.CursorForward(3) }, "\x1b[3C"},
+		{func() { o.CursorBack(4) }, "\x1b[4D"},
+		{func() { o.CursorNextLine(1) }, "\x1b[1E"},
+		{func() { o.CursorPrevLine(1) }, "\x1b[1F"},
+		{func() { o.CursorPos(1, 2) }, "\x1b[1;2H"},
+		{o.CursorSave, "\x1b[s"},
+		{o.CursorRestore, "\x1b[u"},
+		{o.HideCursor, "\x1b[?25l"},
+		{o.ShowCursor, "\x1b[?25h"},
+		{o.EnterAltScreen, "\x1b[?1049h"},
+		{o.ExitAltScreen, "\x1b[?1049l"},
+		{func() { o.Draw(ClearLineNext) }, "\x1b[0K"},
+		{
+			func() { o.DrawSgr("hello world", SgrBold, SgrFaint, SgrItalic, SgrUnderLine, SgrReverse) },
+			"\x1b[1;2;3;4;7mhello world\x1b[m",
+		},
+	}
+
+	for i, tt := range tests {
+		buf.Reset()
+		tt.draw()
+		if got := buf.String(); got != tt.want {
+			t.Errorf("#%d drew %q, want %q", i, got, tt.want)
+		}
+	}
+}
+
+func TestSetOutput(t *testing.T) {
+	var buf bytes.Buffer
+	old := StdOutput().w
+	SetOutput(&buf)
+	defer SetOutput(old)
+
+	DrawAt(Point{X: 1, Y: 1}, "x")
+	CursorBack(1)
+	if got, want := buf.String(), "\x1b[2;2Hx\x1b[1D"; got != want {
+		t.Errorf("package output = %q, want %q", got, want)
+	}
 }
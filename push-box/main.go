@@ -1,14 +1,32 @@
 package main
 
 import (
-	"github.com/zhaowk/game"
+	"flag"
+	"fmt"
 	"os"
+
+	"github.com/zhaowk/game"
 )
 
 func main() {
-	if len(os.Args) == 2 {
-		game.RunGame(&pushBoxMul{}, os.Args[1])
+	r := game.NewRunner()
+	r.SetName("push-box")
+	r.Flags(flag.CommandLine)
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "usage: %s [options] [map]\n", os.Args[0])
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	var res game.Result
+	if flag.NArg() == 1 {
+		res = r.Run(&pushBoxMul{}, flag.Arg(0))
 	} else {
-		game.RunGame(&pushBoxMul{})
+		res = r.Run(&pushBoxMul{})
+	}
+	if res.Err != nil {
+		fmt.Fprintln(os.Stderr, res.Err)
+		os.Exit(1)
 	}
+	fmt.Fprintf(os.Stderr, "%v, score %d, seed %d\n", res.Outcome, res.Score, res.Seed)
 }
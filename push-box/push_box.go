@@ -61,6 +61,24 @@ func (g gameItem) fromByte(b byte) gameItem {
 	}
 }
 
+// role the role of the item in the theme
+func (g gameItem) role() string {
+	switch g {
+	case PushBoxTarget:
+		return game.RoleTarget
+	case PushBoxBox:
+		return game.RoleBox
+	case PushBoxTargetBox:
+		return game.RoleBoxTarget
+	case PushBoxPerson, PushBoxPersonTarget:
+		return game.RolePlayer
+	case PushBoxWall:
+		return game.RoleWall
+	default:
+		return game.RoleText
+	}
+}
+
 func (g gameItem) String() string {
 	return fmt.Sprintf("%c", g.toByte())
 }
@@ -86,61 +104,37 @@ type pushBox struct {
 	width      int
 	height     int
 	msg        string
-	stop       bool
+	theme      *game.Theme
+	keys       game.Keymap
 }
 
-func (g *pushBox) Init(args ...interface{}) error {
-	if len(args) == 0 {
-		return fmt.Errorf("empty game")
-	}
-
-	if m, ok := args[0].(boxMap); !ok {
-		return fmt.Errorf("unknown game")
-	} else if err := g.init(m); err != nil {
-		return err
-	}
-	return nil
-}
-
-func (g *pushBox) Run(k int, _ string) {
-	switch k {
-	case 'w', 'W', game.SysUp:
+func (g *pushBox) update(a game.Action) {
+	switch a {
+	case game.ActionUp:
 		g.move(-1, 0)
-	case 's', 'S', game.SysDown:
+	case game.ActionDown:
 		g.move(1, 0)
-	case 'a', 'A', game.SysLeft:
+	case game.ActionLeft:
 		g.move(0, -1)
-	case 'd', 'D', game.SysRight:
+	case game.ActionRight:
 		g.move(0, 1)
-	case 'r', 'R':
+	case game.ActionReset:
 		_ = g.init(g.original)
-	case 'q', 'Q':
-		g.stop = true
 	}
-	g.draw()
 }
 
-func (g *pushBox) Next() bool {
-	if g.stop {
-		return false
-	}
-
+// solved whether all boxes are on the targets
+func (g *pushBox) solved() bool {
 	for i := range g.runtime {
 		for j := range g.runtime[i] {
 			switch g.runtime[i][j] {
 			case PushBoxPersonTarget, PushBoxTarget, PushBoxBox:
-				return true
+				return false
 			}
 		}
 	}
 
-	return false
-}
-
-func (g *pushBox) Finish() {
-	g.msg = "congratulations!"
-	g.draw()
-	time.Sleep(300 * time.Millisecond)
+	return true
 }
 
 func (g *pushBox) validMap(pane boxMap) (game.Point, bool) {
@@ -192,7 +186,7 @@ func (g *pushBox) init(pane boxMap) error {
 	}
 
 	g.runPerson = g.origPerson
-	g.draw()
+	g.msg = ""
 	return nil
 }
 
@@ -232,39 +226,51 @@ func (g *pushBox) doMove(x, y int) {
 	g.msg = ""
 }
 
-func (g *pushBox) draw() {
-	if g.width <= 0 || g.height <= 0 {
-		return
-	}
+// layout the map and its size below, the messages at right
+func (g *pushBox) layout() game.Widget {
+	return game.HBox{Gap: 2, Children: []game.Widget{
+		game.VBox{Children: []game.Widget{
+			game.Canvas{Rows: g.height, Cols: g.width, Paint: g.drawMap},
+			game.Label{Text: fmt.Sprintf("height:%d, width:%d", g.height, g.width)},
+		}},
+		game.VBox{Children: []game.Widget{
+			game.Box{Title: "Tips", Style: g.theme.Style(game.RoleBorder), Padding: 1, Child: game.VBox{Children: []game.Widget{
+				game.Label{Text: "push all `o` to `.`"},
+				game.KeyHelp{Bindings: g.keys},
+			}}},
+			game.Label{Text: g.msg, Width: 20},
+		}},
+	}}
+}
 
-	// clear screen & move curse to (0, 0)
-	game.Clear()
-	game.Cursor(game.Point{})
+// pushBoxKeys the default bindings, see ~/.config/push-box/keys.toml
+var pushBoxKeys = game.Keymap{
+	{Action: game.ActionUp, Keys: []game.Key{game.KeyRune('w'), game.KeyCode(game.SysUp)}, Help: "up"},
+	{Action: game.ActionDown, Keys: []game.Key{game.KeyRune('s'), game.KeyCode(game.SysDown)}, Help: "down"},
+	{Action: game.ActionLeft, Keys: []game.Key{game.KeyRune('a'), game.KeyCode(game.SysLeft)}, Help: "left"},
+	{Action: game.ActionRight, Keys: []game.Key{game.KeyRune('d'), game.KeyCode(game.SysRight)}, Help: "right"},
+	{Action: game.ActionReset, Keys: []game.Key{game.KeyRune('r')}, Help: "reset"},
+	{Action: game.ActionMenu, Keys: []game.Key{game.KeyCode(game.SysEsc)}, Help: "menu"},
+	{Action: game.ActionQuit, Keys: []game.Key{game.KeyRune('q')}, Help: "exit"},
+}
 
-	// panel
-	for _, s := range g.runtime {
-		for _, c := range s {
-			game.Draw(c.String())
+func (g *pushBox) drawMap(sc *game.Screen) {
+	for i, s := range g.runtime {
+		for j, c := range s {
+			sc.Set(game.Point{X: i, Y: j}, rune(c.toByte()), g.theme.Style(c.role()))
 		}
-		game.DrawLine("")
 	}
-
-	// messages at right
-	game.DrawAt(game.Point{Y: g.width + 4}, "Tips: push all `o` to `.`")
-	game.DrawAt(game.Point{X: 1, Y: g.width + 4}, "press w,s,a,d to move `p`")
-	game.DrawAt(game.Point{X: 2, Y: g.width + 4}, "press r to reset, q to exit")
-	game.DrawAt(game.Point{X: 3, Y: g.width + 4}, g.msg)
-	game.DrawAt(game.Point{X: g.height}, fmt.Sprintf("height:%d, width:%d", g.height, g.width))
 }
 
 type pushBoxMul struct {
-	maps []boxMap
-	idx  int
-	curr *pushBox
-	stop bool
+	maps   []boxMap
+	idx    int
+	curr   *pushBox
+	solved bool // showing the message before the next map
+	runner *game.Runner
 }
 
-func (g *pushBoxMul) Init(args ...interface{}) (err error) {
+func (g *pushBoxMul) Init(r *game.Runner, args ...interface{}) (err error) {
 	if len(args) == 0 {
 		g.maps = defaultMaps
 	} else if len(args) > 1 {
@@ -274,39 +280,76 @@ func (g *pushBoxMul) Init(args ...interface{}) (err error) {
 	}
 
 	if err != nil || len(g.maps) == 0 {
-		return fmt.Errorf("error: %v", err.Error())
+		return fmt.Errorf("error: %v", err)
 	}
 
-	g.curr = &pushBox{}
-	return g.curr.Init(g.maps[0])
-}
+	keys, err := r.Keymap(pushBoxKeys)
+	if err != nil {
+		return err
+	}
 
-func (g *pushBoxMul) Run(k int, s string) {
-	switch k {
-	case 'q', 'Q':
-		g.stop = true
-	default:
-		g.curr.Run(k, s)
+	g.runner = r
+	g.curr = &pushBox{theme: r.Theme(), keys: keys}
+	if err = g.curr.init(g.maps[0]); err == nil {
+		r.SetMinSize(g.curr.layout().Size())
+		r.EnableMenu(g.reset)
 	}
+	return
+}
+
+// reset start again from the first map
+func (g *pushBoxMul) reset() {
+	g.idx = -1
+	g.runner.SetScore(0)
+	g.nextMap()
 }
 
-func (g *pushBoxMul) Next() bool {
-	if g.stop {
-		return false
+func (g *pushBoxMul) Update(e game.Event) {
+	switch e := e.(type) {
+	case game.Key:
+		if a := g.curr.keys.Action(e); a == game.ActionQuit {
+			g.runner.Quit()
+		} else if !g.solved {
+			g.curr.update(a)
+			g.check()
+		}
+	case game.TickEvent:
+		if g.solved {
+			g.nextMap()
+		}
 	}
+}
+
+func (g *pushBoxMul) Render(sc *game.Screen) {
+	game.DrawCentered(sc, g.curr.layout())
+}
 
-	if g.curr.Next() {
-		return true
-	} else {
-		g.curr.Finish()
-		g.idx++
+func (g *pushBoxMul) Finish() {}
+
+// check show the message for a while when the map is solved, the score is the maps solved
+func (g *pushBoxMul) check() {
+	if g.curr.solved() {
+		g.curr.msg = "congratulations!"
+		g.solved = true
+		g.runner.SetScore(g.idx + 1)
+		g.runner.SetTick(300 * time.Millisecond)
 	}
+}
 
-	if g.idx >= len(g.maps) {
-		return false
+func (g *pushBoxMul) nextMap() {
+	g.solved = false
+	g.runner.SetTick(0)
+
+	if g.idx++; g.idx >= len(g.maps) {
+		g.curr.msg = "all maps solved!"
+		g.runner.GameOver(game.OutcomeWin)
+		return
 	}
 
-	return nil == g.curr.init(g.maps[g.idx])
+	if err := g.curr.init(g.maps[g.idx]); err != nil {
+		g.curr.msg = err.Error()
+		g.runner.Quit()
+		return
+	}
+	g.runner.SetMinSize(g.curr.layout().Size())
 }
-
-func (g *pushBoxMul) Finish() {}
@@ -0,0 +1,44 @@
+package main
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/zhaowk/game"
+	"github.com/zhaowk/game/gametest"
+)
+
+func TestDraw(t *testing.T) {
+	g := &pushBox{keys: pushBoxKeys}
+	if err := g.init(defaultMaps[0]); err != nil {
+		t.Fatal(err)
+	}
+	g.update(game.ActionLeft)
+
+	vt := gametest.Render(11, 42, func(sc *game.Screen) { game.DrawWidget(sc, game.Point{}, g.layout()) })
+	gametest.Golden(t, "testdata/draw.golden", vt.String())
+
+	if c := vt.Cell(1, 4); c.Rune != 'p' {
+		t.Errorf("player cell = %q, want 'p'", c.Rune)
+	}
+}
+
+func TestPlay(t *testing.T) {
+	h := gametest.Start(t, &pushBoxMul{}, 12, 50)
+
+	h.Type("\rssaawsaw") // new game from the start menu, then solve the first map
+	if got := h.VT.String(); !strings.Contains(got, "congratulations!") {
+		t.Fatalf("screen:\n%s\nwant the map solved", got)
+	}
+
+	h.Wait(300 * time.Millisecond)
+	if got := h.VT.String(); !strings.Contains(got, "height:5, width:13") {
+		t.Errorf("screen:\n%s\nwant the second map", got)
+	}
+
+	h.Type("q")
+	if res := h.Stop(); res.Outcome != game.OutcomeQuit || res.Score != 1 {
+		t.Errorf("result = %+v, want one map solved", res)
+	}
+}
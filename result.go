@@ -0,0 +1,37 @@
+package game
+
+import "time"
+
+// Outcome how a game ended
+type Outcome uint8
+
+const (
+	OutcomeQuit        Outcome = iota // the player quit
+	OutcomeWin                        // the player won
+	OutcomeLose                       // game over
+	OutcomeInterrupted                // SIGINT/SIGTERM, or the context was canceled
+)
+
+// String outcome name
+func (o Outcome) String() string {
+	switch o {
+	case OutcomeQuit:
+		return "quit"
+	case OutcomeWin:
+		return "win"
+	case OutcomeLose:
+		return "lose"
+	case OutcomeInterrupted:
+		return "interrupted"
+	}
+	return ""
+}
+
+// Result what RunGame and RunEventGame return when the game is over
+type Result struct {
+	Outcome  Outcome
+	Score    int
+	Duration time.Duration
+	Seed     int64 // the seed of Runner.Rand, to replay the game
+	Err      error // why the game did not start, e.g. no terminal or a bad keys.toml, then nothing else is set
+}
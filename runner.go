@@ -0,0 +1,530 @@
+//go:build linux || darwin
+
+package game
+
+import (
+	"context"
+	"flag"
+	"math/rand"
+	"os"
+	"os/signal"
+	"time"
+
+	"golang.org/x/sys/unix"
+)
+
+// EventGame a game driven by events. All methods are called on the runner goroutine,
+// so the game state needs no locking.
+type EventGame interface {
+	// Init prepare the game, and configure the runner, e.g. SetTick
+	Init(r *Runner, args ...interface{}) error
+	// Update handle an event
+	Update(e Event)
+	// Render draw the game on the cleared screen, called after Init, after every event,
+	// and once more when the game ends by itself. Only the changed cells are sent to the terminal.
+	Render(s *Screen)
+	// Finish called once after the loop ends
+	Finish()
+}
+
+// Runner the loop of an EventGame, merging keys, ticks, resizes and quit into one goroutine.
+// Run drives the loop, while Start, Dispatch and Close drive it step by step, e.g. in tests.
+type Runner struct {
+	name       string  // name of the game, for its config files
+	in         Input   // nil for the session terminal
+	out        *Output // nil for the package level output
+	clock      Clock
+	seed       int64 // 0 for a seed from the clock
+	rand       *rand.Rand
+	theme      *Theme
+	colors     *ColorProfile // nil to keep the profile of the output
+	rows, cols int           // fixed size, 0 for the terminal size
+	minRows    int           // the screen size the game needs
+	minCols    int
+
+	game    EventGame
+	screen  *Screen // nil when the game draws by itself
+	modals  []Modal // shown over the game, the last on top
+	newGame func()  // reset the game from the menu, nil without the menu
+	keymap  Keymap  // bindings of the game, nil if it has none
+	paused  bool
+	tick    time.Duration
+	stop    bool
+	start   time.Time
+	result  Result
+}
+
+// NewRunner runner on the session terminal
+func NewRunner() *Runner {
+	return &Runner{clock: SystemClock}
+}
+
+// SetName set the name of the game, which finds its config files, e.g. ~/.config/<name>/keys.toml
+func (r *Runner) SetName(name string) {
+	r.name = name
+}
+
+// Keymap the bindings of the game: `defaults`, with those of ~/.config/<name>/keys.toml over them
+// when the name is set. The game menu opens with the keys of ActionMenu.
+func (r *Runner) Keymap(defaults Keymap) (Keymap, error) {
+	km := defaults
+	if r.name != "" {
+		var err error
+		if km, err = LoadKeymap(KeymapPath(r.name), defaults); err != nil {
+			return nil, err
+		}
+	}
+	r.keymap = km
+	return km, nil
+}
+
+// SetInput read keys from `in` instead of the session terminal
+func (r *Runner) SetInput(in Input) {
+	r.in = in
+}
+
+// SetOutput draw to `o` instead of the package level output
+func (r *Runner) SetOutput(o *Output) {
+	r.out = o
+}
+
+// SetClock take the time and ticks from `c` instead of SystemClock
+func (r *Runner) SetClock(c Clock) {
+	r.clock = c
+}
+
+// Clock the time source of the game
+func (r *Runner) Clock() Clock {
+	return r.clock
+}
+
+// SetSeed seed Rand with `seed` to replay a game, 0 for a seed from the clock
+func (r *Runner) SetSeed(seed int64) {
+	r.seed = seed
+}
+
+// Seed the seed of Rand, known after Start
+func (r *Runner) Seed() int64 {
+	return r.seed
+}
+
+// Rand the random source of the game, the same seed gives the same game
+func (r *Runner) Rand() *rand.Rand {
+	return r.rand
+}
+
+// SetTheme draw the game with theme `t` instead of DefaultTheme
+func (r *Runner) SetTheme(t *Theme) {
+	r.theme = t
+}
+
+// Theme the theme of the game
+func (r *Runner) Theme() *Theme {
+	if r.theme == nil {
+		return DefaultTheme
+	}
+	return r.theme
+}
+
+// Flags register the options of the runner on `fs`, e.g. flag.CommandLine:
+//
+//	--seed n      seed of the game, 0 for a random one
+//	--theme path  theme file, see LoadTheme
+//	--color p     colors of the terminal: auto, none, 8, 256 or truecolor
+func (r *Runner) Flags(fs *flag.FlagSet) {
+	fs.Int64Var(&r.seed, "seed", 0, "seed of the game, 0 for a random one")
+	fs.Var(themeFlag{r}, "theme", "theme `file` in JSON")
+	fs.Var(colorsFlag{r}, "color", "`colors` of the terminal: auto, none, 8, 256 or truecolor")
+}
+
+// colorsFlag set the color profile of the runner from the flag value
+type colorsFlag struct {
+	r *Runner
+}
+
+func (f colorsFlag) String() string {
+	if f.r == nil || f.r.colors == nil {
+		return "auto"
+	}
+	return f.r.colors.String()
+}
+
+func (f colorsFlag) Set(s string) error {
+	if s == "auto" {
+		f.r.colors = nil
+		return nil
+	}
+
+	p, err := ParseColorProfile(s)
+	if err == nil {
+		f.r.colors = &p
+	}
+	return err
+}
+
+// themeFlag load the theme of the runner from the flag value
+type themeFlag struct {
+	r *Runner
+}
+
+func (f themeFlag) String() string {
+	if f.r == nil || f.r.theme == nil {
+		return ""
+	}
+	return f.r.theme.Name
+}
+
+func (f themeFlag) Set(path string) (err error) {
+	f.r.theme, err = LoadTheme(path)
+	return
+}
+
+// SetSize use a screen of `rows` x `cols` instead of the terminal size
+func (r *Runner) SetSize(rows, cols int) {
+	r.rows, r.cols = rows, cols
+}
+
+// SetMinSize the screen size the game needs. While the screen is smaller, the runner
+// shows the "terminal too small" overlay instead of the game and holds the ticks.
+func (r *Runner) SetMinSize(rows, cols int) {
+	r.minRows, r.minCols = rows, cols
+}
+
+// Size rows and cols of the screen, the game receives a ResizeEvent when they change
+func (r *Runner) Size() (rows, cols int) {
+	if r.screen != nil {
+		return r.screen.Size()
+	}
+	return r.size()
+}
+
+// SetTick deliver a TickEvent every `d`, 0 to stop ticking
+func (r *Runner) SetTick(d time.Duration) {
+	r.tick = d
+}
+
+// Tick the interval of TickEvent, 0 if not ticking
+func (r *Runner) Tick() time.Duration {
+	return r.tick
+}
+
+// Quit end the loop after the current event, as the player quit
+func (r *Runner) Quit() {
+	r.End(OutcomeQuit)
+}
+
+// End end the loop after the current event with outcome `o`
+func (r *Runner) End(o Outcome) {
+	r.result.Outcome = o
+	r.stop = true
+}
+
+// SetScore set the score reported in Result
+func (r *Runner) SetScore(score int) {
+	r.result.Score = score
+}
+
+// ShowModal show `m` over the game. It takes the keys until it closes, and the ticks are held meanwhile.
+func (r *Runner) ShowModal(m Modal) {
+	r.modals = append(r.modals, m)
+}
+
+// CloseModal close `m` if shown
+func (r *Runner) CloseModal(m Modal) {
+	for i := range r.modals {
+		if r.modals[i] == m {
+			r.modals = append(r.modals[:i], r.modals[i+1:]...)
+			return
+		}
+	}
+}
+
+// Modal the modal on top, nil if none
+func (r *Runner) Modal() Modal {
+	if len(r.modals) == 0 {
+		return nil
+	}
+	return r.modals[len(r.modals)-1]
+}
+
+// SetPaused pause or resume the game. While paused the ticks are held, the keys go to no one
+// but the menus, and the PAUSED overlay is shown.
+func (r *Runner) SetPaused(paused bool) {
+	r.paused = paused
+}
+
+// Paused whether the game is paused
+func (r *Runner) Paused() bool {
+	return r.paused
+}
+
+// EnableFocus turn on focus reporting if the input is a terminal, reports are delivered as FocusEvent.
+// Games binding ActionPause have it on, and pause when the terminal loses the focus.
+func (r *Runner) EnableFocus() {
+	if t, ok := r.in.(*Terminal); ok {
+		t.SetOutput(r.output())
+		t.EnableFocus()
+	}
+}
+
+// EnableMouse turn on mouse tracking if the input is a terminal, reports are delivered as MouseEvent
+func (r *Runner) EnableMouse() {
+	if t, ok := r.in.(*Terminal); ok {
+		t.SetOutput(r.output())
+		t.EnableMouse()
+	}
+}
+
+// RunEventGame run EventGame with args. The terminal stays in raw mode until the game finishes,
+// and is restored on return, on panic and on SIGINT/SIGTERM.
+func RunEventGame(g EventGame, args ...interface{}) Result {
+	return NewRunner().Run(g, args...)
+}
+
+// RunEventGameContext run EventGame with args until the game ends or `ctx` is done,
+// Finish is called in both cases before the terminal is restored.
+func RunEventGameContext(ctx context.Context, g EventGame, args ...interface{}) Result {
+	return NewRunner().RunContext(ctx, g, args...)
+}
+
+// Run run EventGame with args until the game ends
+func (r *Runner) Run(g EventGame, args ...interface{}) Result {
+	return r.RunContext(context.Background(), g, args...)
+}
+
+// RunContext run EventGame with args until the game ends or `ctx` is done.
+// The game is drawn on the alternate screen with the cursor hidden, both are restored on return and on panic.
+// An error opening the terminal or of Init is returned in Result.Err, after the terminal is restored.
+func (r *Runner) RunContext(ctx context.Context, g EventGame, args ...interface{}) Result {
+	if r.in == nil {
+		in, err := stdInput()
+		if err != nil { // e.g. no terminal to play in
+			return Result{Err: err}
+		}
+		r.in = in
+		defer closeStdInput()
+	}
+
+	out := r.output()
+	out.EnterAltScreen()
+	out.HideCursor()
+	defer func() {
+		if t, ok := r.in.(*Terminal); ok { // on this goroutine, also after a signal closed the input
+			t.DisableMouse()
+			t.DisableFocus()
+		}
+		out.ShowCursor()
+		out.ExitAltScreen()
+	}()
+
+	if err := r.Start(g, args...); err != nil {
+		return Result{Err: err}
+	}
+
+	r.loop(ctx)
+	return r.Close()
+}
+
+// Start init the game and draw the first frame
+func (r *Runner) Start(g EventGame, args ...interface{}) error {
+	if g == nil {
+		panic("empty game")
+	}
+
+	r.game = g
+	r.start = r.clock.Now()
+	if r.seed == 0 {
+		r.seed = r.start.UnixNano()
+	}
+	r.rand = rand.New(rand.NewSource(r.seed))
+	r.result.Seed = r.seed
+	r.screen = NewScreen(r.size())
+	if r.out != nil {
+		r.screen.SetOutput(r.out)
+	}
+	if r.colors != nil {
+		r.output().SetColorProfile(*r.colors)
+	}
+
+	if err := g.Init(r, args...); err != nil {
+		return err
+	}
+	if r.newGame != nil {
+		r.ShowModal(r.gameMenu(true))
+	}
+	if r.pausable() {
+		r.EnableFocus()
+	}
+
+	if !r.stop {
+		r.render()
+	}
+	return nil
+}
+
+// Dispatch deliver an event to the game and draw the next frame, ignored once the game ended
+func (r *Runner) Dispatch(e Event) {
+	if r.stop {
+		return
+	}
+
+	switch e := e.(type) {
+	case ResizeEvent:
+		if r.screen != nil {
+			r.screen.Resize(e.Rows, e.Cols)
+		}
+	case TickEvent:
+		if r.held() {
+			return
+		}
+	case MouseEvent:
+		if r.Modal() != nil || r.paused || r.tooSmall() {
+			return
+		}
+	case FocusEvent:
+		if !e.Focused && r.pausable() {
+			r.paused = true
+		}
+	}
+
+	if k, ok := e.(Key); !ok || !r.menuKey(k) && !r.pauseKey(k) && !r.tooSmall() { // the game is hidden while too small
+		r.game.Update(e)
+	}
+	if _, ok := e.(QuitEvent); ok {
+		r.stop = true
+	}
+
+	if !r.stop {
+		r.render()
+	}
+}
+
+// Running whether the game has not ended
+func (r *Runner) Running() bool {
+	return !r.stop
+}
+
+// Close end the game: draw the final frame unless interrupted, call Finish and return the result
+func (r *Runner) Close() Result {
+	r.stop = true
+	if r.result.Outcome != OutcomeInterrupted { // show the final state
+		r.render()
+	}
+	r.game.Finish()
+
+	r.result.Duration = r.clock.Now().Sub(r.start)
+	return r.result
+}
+
+func (r *Runner) loop(ctx context.Context) {
+	winch := make(chan os.Signal, 1)
+	signal.Notify(winch, unix.SIGWINCH)
+	defer signal.Stop(winch)
+
+	var (
+		tick   <-chan time.Time
+		ticker Ticker
+		rate   time.Duration
+	)
+	defer func() {
+		if ticker != nil {
+			ticker.Stop()
+		}
+	}()
+
+	for !r.stop {
+		want := r.tick
+		if r.held() {
+			want = 0
+		}
+		if rate != want { // changed by Init or Update, or held
+			if ticker != nil {
+				ticker.Stop()
+				ticker, tick = nil, nil
+			}
+			if rate = want; rate > 0 {
+				ticker = r.clock.NewTicker(rate)
+				tick = ticker.C()
+			}
+		}
+
+		var e Event
+		select {
+		case k, ok := <-r.in.Keys():
+			if !ok { // input closed, e.g. the terminal by a signal
+				e = QuitEvent{}
+				r.result.Outcome = OutcomeInterrupted
+			} else {
+				e = k.Event()
+			}
+		case now := <-tick:
+			e = TickEvent{Time: now}
+		case <-winch:
+			rows, cols := r.size()
+			e = ResizeEvent{Rows: rows, Cols: cols}
+		case <-ctx.Done():
+			e = QuitEvent{}
+			r.result.Outcome = OutcomeInterrupted
+		}
+
+		r.Dispatch(e)
+	}
+}
+
+// output the output of the screen
+func (r *Runner) output() *Output {
+	if r.out != nil {
+		return r.out
+	}
+	return std
+}
+
+// render draw a frame of the game and send the changes to the output
+func (r *Runner) render() {
+	if r.screen == nil {
+		r.game.Render(nil)
+		return
+	}
+
+	r.screen.Clear()
+	if !r.screen.TooSmall(r.minRows, r.minCols) {
+		r.game.Render(r.screen)
+		if m := r.Modal(); m != nil {
+			drawModal(r.screen, m)
+		} else if r.paused {
+			drawModal(r.screen, r.pausedBox())
+		}
+	}
+	_ = r.screen.Flush()
+}
+
+// held whether the ticks are held: the player can not see the game, is in a menu or paused
+func (r *Runner) held() bool {
+	return r.tooSmall() || r.Modal() != nil || r.paused
+}
+
+// tooSmall whether the screen is smaller than the game needs
+func (r *Runner) tooSmall() bool {
+	if r.screen == nil {
+		return false
+	}
+	rows, cols := r.screen.Size()
+	return rows < r.minRows || cols < r.minCols
+}
+
+// size the fixed size, or the terminal size, 24x80 if unknown
+func (r *Runner) size() (rows, cols int) {
+	if r.rows > 0 && r.cols > 0 {
+		return r.rows, r.cols
+	}
+
+	if t, ok := r.in.(*Terminal); ok {
+		rows, cols = t.Size()
+	} else {
+		rows, cols = Size()
+	}
+	if rows <= 0 || cols <= 0 {
+		return 24, 80
+	}
+	return
+}
@@ -2,107 +2,97 @@ package main
 
 import (
 	"github.com/zhaowk/game"
-	"sort"
-	"strings"
 )
 
-type block interface {
-	Switch() block
-	Points() []game.Point
-	GetLine(n int) string
+// block a tetromino in one of the four rotation states of the super rotation system (SRS):
+// 0 as spawned, 1 after a clockwise rotation, 2 after two, 3 after a counter-clockwise one
+type block struct {
+	kind  byte // one of I, O, T, S, Z, J, L
+	state int
 }
 
-type block2 struct {
-	base []game.Point
+// shape a tetromino as spawned in its rotation box
+type shape struct {
+	size  int // the box is size x size, the piece rotates around its centre
+	cells []game.Point
 }
 
-func (b block2) Points() []game.Point {
-	return b.base
+var shapes = map[byte]shape{
+	'O': {2, []game.Point{{X: 0, Y: 0}, {X: 0, Y: 1}, {X: 1, Y: 0}, {X: 1, Y: 1}}}, // ::
+	'L': {3, []game.Point{{X: 0, Y: 2}, {X: 1, Y: 0}, {X: 1, Y: 1}, {X: 1, Y: 2}}}, // ..:
+	'J': {3, []game.Point{{X: 0, Y: 0}, {X: 1, Y: 0}, {X: 1, Y: 1}, {X: 1, Y: 2}}}, // :..
+	'S': {3, []game.Point{{X: 0, Y: 1}, {X: 0, Y: 2}, {X: 1, Y: 0}, {X: 1, Y: 1}}}, // .:'
+	'Z': {3, []game.Point{{X: 0, Y: 0}, {X: 0, Y: 1}, {X: 1, Y: 1}, {X: 1, Y: 2}}}, // ':.
+	'T': {3, []game.Point{{X: 0, Y: 1}, {X: 1, Y: 0}, {X: 1, Y: 1}, {X: 1, Y: 2}}}, // .:.
+	'I': {4, []game.Point{{X: 1, Y: 0}, {X: 1, Y: 1}, {X: 1, Y: 2}, {X: 1, Y: 3}}}, // ....
 }
 
-func (b block2) Switch() block {
-	return &block2{base: b.base}
-}
-
-func (b block2) GetLine(n int) string {
-	if n < 2 {
-		return strings.Repeat(russiaBlockBlk, 2)
+// kicks the offsets a clockwise rotation from each state tries in order, as (x, y) of the SRS tables
+// with x to the right and y up. A counter-clockwise rotation from state s tries those from s-1 negated.
+var (
+	kicksJLSTZ = [4][5][2]int{
+		{{0, 0}, {-1, 0}, {-1, 1}, {0, -2}, {-1, -2}}, // 0 -> R
+		{{0, 0}, {1, 0}, {1, -1}, {0, 2}, {1, 2}},     // R -> 2
+		{{0, 0}, {1, 0}, {1, 1}, {0, -2}, {1, -2}},    // 2 -> L
+		{{0, 0}, {-1, 0}, {-1, -1}, {0, 2}, {-1, 2}},  // L -> 0
 	}
-	return ""
-}
+	kicksI = [4][5][2]int{
+		{{0, 0}, {-2, 0}, {1, 0}, {-2, -1}, {1, 2}}, // 0 -> R
+		{{0, 0}, {-1, 0}, {2, 0}, {-1, 2}, {2, -1}}, // R -> 2
+		{{0, 0}, {2, 0}, {-1, 0}, {2, 1}, {-1, -2}}, // 2 -> L
+		{{0, 0}, {1, 0}, {-2, 0}, {1, -2}, {-2, 1}}, // L -> 0
+	}
+)
 
-type block3 struct {
-	base []game.Point
+func (b block) Kind() byte {
+	return b.kind
 }
 
-func (b block3) Points() []game.Point {
-	return b.base
+// Size the size of the rotation box
+func (b block) Size() int {
+	return shapes[b.kind].size
 }
 
-func (b block3) Switch() block {
-	points := make([]game.Point, len(b.base))
-	for i, p := range b.base {
-		switch p.X {
-		case 0:
-			points[i] = game.Point{X: p.Y, Y: 2}
-		case 1:
-			points[i] = game.Point{X: p.Y, Y: p.X}
-		case 2:
-			points[i] = game.Point{X: p.Y}
+// Points the cells in the rotation box
+func (b block) Points() []game.Point {
+	s := shapes[b.kind]
+	points := make([]game.Point, len(s.cells))
+	for i, p := range s.cells {
+		for j := 0; j < b.state; j++ { // 顺时针转 90 度
+			p = game.Point{X: p.Y, Y: s.size - 1 - p.X}
 		}
+		points[i] = p
 	}
-
-	sort.Slice(points, func(i, j int) bool {
-		return points[i].Less(points[j])
-	})
-	return &block3{base: points}
+	return points
 }
 
-func (b block3) GetLine(n int) string {
-	s := make([]byte, 3)
-	s[0] = russiaBlockEmpty[0]
-	s[1] = russiaBlockEmpty[0]
-	s[2] = russiaBlockEmpty[0]
-	if n < 3 {
-		for _, p := range b.base {
-			if p.X == n {
-				s[p.Y] = russiaBlockBlk[0]
-			}
-		}
+// Rotate the block rotated clockwise if `dir` > 0, else counter-clockwise
+func (b block) Rotate(dir int) block {
+	if dir > 0 {
+		b.state = (b.state + 1) % 4
+	} else {
+		b.state = (b.state + 3) % 4
 	}
-	return string(s)
+	return b
 }
 
-type block4 struct {
-	base []game.Point
-}
-
-func (b block4) Points() []game.Point {
-	return b.base
-}
-
-func (b block4) Switch() block {
-	var points []game.Point
-	if b.base[1].X == 0 { // 横 -> 竖
-		points = []game.Point{{0, 0}, {1, 0}, {2, 0}, {3, 0}}
-	} else { // 竖 -> 横
-		points = []game.Point{{0, 0}, {0, 1}, {0, 2}, {0, 3}}
+// Kicks the offsets of the position to try in order when rotating as Rotate(dir)
+func (b block) Kicks(dir int) []game.Point {
+	table := &kicksJLSTZ
+	switch b.kind {
+	case 'O':
+		return []game.Point{{}}
+	case 'I':
+		table = &kicksI
 	}
-	return &block4{base: points}
-}
 
-func (b block4) GetLine(n int) string {
-	s := make([]byte, 4)
-	s[0] = russiaBlockEmpty[0]
-	s[1] = russiaBlockEmpty[0]
-	s[2] = russiaBlockEmpty[0]
-	s[3] = russiaBlockEmpty[0]
-	if n < 4 {
-		for _, p := range b.base {
-			if p.X == n {
-				s[p.Y] = russiaBlockBlk[0]
-			}
-		}
+	from, sign := b.state, 1
+	if dir <= 0 {
+		from, sign = (b.state+3)%4, -1
+	}
+	kicks := make([]game.Point, len(table[from]))
+	for i, k := range table[from] {
+		kicks[i] = game.Point{X: -sign * k[1], Y: sign * k[0]}
 	}
-	return string(s)
+	return kicks
 }
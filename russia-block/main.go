@@ -1,7 +1,30 @@
 package main
 
-import "github.com/zhaowk/game"
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/zhaowk/game"
+)
 
 func main() {
-	game.RunGame(&russiaBlock{})
+	r := game.NewRunner()
+	r.SetName("russia-block")
+	r.Flags(flag.CommandLine)
+	b := &russiaBlock{}
+	flag.StringVar(&b.random, "random", "7-bag", "`randomizer` of the blocks: 7-bag, nes or random")
+	flag.IntVar(&b.preview, "preview", 3, "number of the next blocks shown, 1 to 6")
+	flag.Parse()
+	if err := b.checkFlags(); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	}
+
+	res := r.Run(b)
+	if res.Err != nil {
+		fmt.Fprintln(os.Stderr, res.Err)
+		os.Exit(1)
+	}
+	fmt.Fprintf(os.Stderr, "%v, score %d, seed %d\n", res.Outcome, res.Score, res.Seed)
 }
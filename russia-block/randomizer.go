@@ -0,0 +1,71 @@
+package main
+
+import (
+	"fmt"
+	"math/rand"
+)
+
+// Randomizer deal the blocks to play, as spawned
+type Randomizer interface {
+	Next() block
+}
+
+// randomizers the names of the randomizers, for the -random flag
+var randomizers = []string{"7-bag", "nes", "random"}
+
+// newRandomizer the randomizer of `name` drawing from `r`
+func newRandomizer(name string, r *rand.Rand) (Randomizer, error) {
+	switch name {
+	case "7-bag":
+		return &bagRandomizer{rand: r}, nil
+	case "nes":
+		return &nesRandomizer{rand: r, last: -1}, nil
+	case "random":
+		return pureRandomizer{rand: r}, nil
+	}
+	return nil, fmt.Errorf("unknown randomizer %q, want one of %v", name, randomizers)
+}
+
+// bagRandomizer deal the seven blocks shuffled in a bag, then the next bag:
+// no block waits more than 12 others
+type bagRandomizer struct {
+	rand *rand.Rand
+	bag  []block
+}
+
+func (g *bagRandomizer) Next() block {
+	if len(g.bag) == 0 {
+		g.bag = make([]block, len(blocks))
+		for i, j := range g.rand.Perm(len(blocks)) {
+			g.bag[i] = blocks[j]
+		}
+	}
+	bl := g.bag[0]
+	g.bag = g.bag[1:]
+	return bl
+}
+
+// nesRandomizer deal as the NES game: roll one of eight, and roll again among the seven
+// on the eighth or on the block just dealt
+type nesRandomizer struct {
+	rand *rand.Rand
+	last int
+}
+
+func (g *nesRandomizer) Next() block {
+	i := g.rand.Intn(len(blocks) + 1)
+	if i == len(blocks) || i == g.last {
+		i = g.rand.Intn(len(blocks))
+	}
+	g.last = i
+	return blocks[i]
+}
+
+// pureRandomizer deal every block uniformly
+type pureRandomizer struct {
+	rand *rand.Rand
+}
+
+func (g pureRandomizer) Next() block {
+	return blocks[g.rand.Intn(len(blocks))]
+}
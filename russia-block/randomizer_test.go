@@ -0,0 +1,63 @@
+package main
+
+import (
+	"math/rand"
+	"testing"
+)
+
+func TestRandomizer(t *testing.T) {
+	for _, name := range randomizers {
+		g, err := newRandomizer(name, rand.New(rand.NewSource(1)))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		seen := make(map[byte]int)
+		for i := 0; i < 700; i++ {
+			bl := g.Next()
+			if bl.state != 0 {
+				t.Errorf("%s: %c dealt in state %d", name, bl.kind, bl.state)
+			}
+			seen[bl.kind]++
+
+			if name == "7-bag" && i%7 == 6 {
+				for _, k := range blocks {
+					if seen[k.kind] != i/7+1 {
+						t.Fatalf("%s: %c dealt %d times in %d bags", name, k.kind, seen[k.kind], i/7+1)
+					}
+				}
+			}
+		}
+		if len(seen) != len(blocks) {
+			t.Errorf("%s: dealt %v", name, seen)
+		}
+	}
+
+	if _, err := newRandomizer("tgm", nil); err == nil {
+		t.Error("unknown randomizer no error")
+	}
+}
+
+func TestPreview(t *testing.T) {
+	b := newTestBlock(t)
+	b.preview = 2
+	b.spawn()
+	next := b.queue[0]
+	if len(b.queue) != 2 {
+		t.Fatalf("queue of %d, want 2", len(b.queue))
+	}
+
+	b.spawn()
+	if b.curr != next || len(b.queue) != 2 {
+		t.Errorf("spawned %c with %d queued, want %c with 2", b.curr.kind, len(b.queue), next.kind)
+	}
+	if b.pos.Y != (b.width-b.curr.Size())/2 {
+		t.Errorf("spawned at %v", b.pos)
+	}
+
+	for _, p := range []int{0, 7} {
+		if err := (&russiaBlock{preview: p, random: "7-bag"}).checkFlags(); (err == nil) != (p == 0) {
+			t.Errorf("checkFlags of preview %d: %v", p, err)
+		}
+	}
+}
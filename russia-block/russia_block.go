@@ -4,7 +4,7 @@ import (
 	"fmt"
 	"github.com/zhaowk/game"
 	"math/rand"
-	"os"
+	"sort"
 	"strings"
 	"time"
 )
@@ -16,157 +16,295 @@ const (
 	russiaBlockEmpty = " "
 )
 
+const (
+	russiaBlockFrame      = 50 * time.Millisecond // the tick, gravity and the lock delay count frames
+	russiaBlockLockDelay  = 10                    // frames a landed block rests before it locks
+	russiaBlockLockResets = 15                    // moves a landed block may make resetting the lock delay, per row reached
+	russiaBlockLevelLines = 10                    // lines cleared to advance a level
+)
+
 var (
-	blocks = []block{
-		&block2{base: []game.Point{{0, 0}, {0, 1}, {1, 0}, {1, 1}}}, // ::
-		&block3{base: []game.Point{{0, 2}, {1, 0}, {1, 1}, {1, 2}}}, // ..:
-		&block3{base: []game.Point{{0, 0}, {1, 0}, {1, 1}, {1, 2}}}, // :..
-		&block3{base: []game.Point{{0, 1}, {0, 2}, {1, 0}, {1, 1}}}, // .:'
-		&block3{base: []game.Point{{0, 0}, {0, 1}, {1, 1}, {1, 2}}}, // ':.
-		&block3{base: []game.Point{{0, 1}, {1, 0}, {1, 1}, {1, 2}}}, // .:.
-		&block4{base: []game.Point{{0, 0}, {0, 1}, {0, 2}, {0, 3}}}, // ....
-	}
+	// russiaBlockGravity the frames the block takes to fall a row at each level from 1, the last for the levels above:
+	// (0.8 - (level-1) * 0.007) ^ (level-1) seconds as the guideline
+	russiaBlockGravity = []int{20, 16, 12, 9, 7, 5, 4, 3, 2, 1}
+	// russiaBlockLinePoints the points of clearing 1 to 4 lines at level 1
+	russiaBlockLinePoints = []int{0, 100, 300, 500, 800}
+)
+
+var (
+	// blocks the seven tetrominoes as spawned, see shapes
+	blocks = []block{{kind: 'O'}, {kind: 'L'}, {kind: 'J'}, {kind: 'S'}, {kind: 'Z'}, {kind: 'T'}, {kind: 'I'}}
 )
 
 type russiaBlock struct {
 	width   int
 	height  int
-	runtime [][]byte
+	runtime [][]byte // kind of the settled blocks, 0 for empty
 	msg     string
+	shown   int64 // the unix second of the time in msg
 	score   int
-	stop    bool
-
-	opCh chan int
-	curr block
-	next block
-	pos  game.Point
+	lines   int  // the lines cleared
+	combo   int  // the locks in a row clearing lines less 1, -1 if the last cleared none
+	b2b     bool // the last clear was a tetris, for the back-to-back bonus
+
+	random  string // the name of the randomizer, 7-bag by default
+	preview int    // the number of the next blocks shown, 1 to 6, 3 by default
+
+	runner     *game.Runner
+	rand       *rand.Rand
+	theme      *game.Theme
+	keys       game.Keymap
+	randomizer Randomizer
+	curr       block
+	queue      []block // the next blocks
+	held       block   // the kind 0 if none
+	holdUsed   bool    // held since the current block spawned
+	pos        game.Point
+
+	fall   int // frames since the block fell a row
+	lock   int // frames the block rested on the stack
+	resets int // moves resetting the lock delay since the block reached its lowest row
+	lowest int // the lowest row the block reached
 }
 
-func (b *russiaBlock) Init(...interface{}) error {
+func (b *russiaBlock) Init(r *game.Runner, _ ...interface{}) (err error) {
+	if b.keys, err = r.Keymap(russiaBlockKeys); err != nil {
+		return err
+	}
+	if err = b.checkFlags(); err != nil {
+		return err
+	}
 	b.width = 10
 	b.height = 15
-	rand.Seed(time.Now().UnixNano())
+	b.rand = r.Rand()
+	b.theme = r.Theme()
+	b.runner = r
+	b.reset()
+
+	widest := *b // the sidebar with the widest values, not to outgrow the terminal in the game
+	widest.score, widest.lines = 99999999, 9999
+	r.SetMinSize(widest.layout().Size())
+	r.EnableMenu(b.reset)
+	r.SetTick(russiaBlockFrame)
+	return nil
+}
+
+// checkFlags check the options of the flags, and default the unset ones
+func (b *russiaBlock) checkFlags() error {
+	if b.random == "" {
+		b.random = "7-bag"
+	}
+	if b.preview == 0 {
+		b.preview = 3
+	}
+	if b.preview < 1 || b.preview > 6 {
+		return fmt.Errorf("preview %d out of 1 to 6", b.preview)
+	}
+	_, err := newRandomizer(b.random, nil)
+	return err
+}
+
+// reset start a new game
+func (b *russiaBlock) reset() {
 	b.runtime = make([][]byte, b.height)
 	for i := 0; i < b.height; i++ {
 		b.runtime[i] = make([]byte, b.width)
 	}
 
-	b.genNext()
-	b.curr = b.next
-	b.pos = game.Point{Y: b.width / 2}
-	b.genNext()
-
-	b.opCh = make(chan int)
+	b.randomizer, _ = newRandomizer(b.random, b.rand) // a new bag
+	b.queue = b.queue[:0]
+	b.held, b.holdUsed = block{}, false
+	b.spawn()
 
-	go b.run()
-	return nil
+	b.score, b.lines, b.combo, b.b2b, b.msg, b.shown = 0, 0, -1, false, "", 0
+	b.runner.SetScore(b.score)
 }
 
-func (b *russiaBlock) Run(k int, _ string) {
-	switch k {
-	case 'w', 'W', game.SysUp:
-		b.opCh <- game.SysUp
-	case 's', 'S', game.SysDown:
-		b.opCh <- game.SysDown
-	case 'a', 'A', game.SysLeft:
-		b.opCh <- game.SysLeft
-	case 'd', 'D', game.SysRight:
-		b.opCh <- game.SysRight
-	case 'q', 'Q':
-		b.stop = true
+func (b *russiaBlock) Update(e game.Event) {
+	switch e := e.(type) {
+	case game.Key:
+		switch b.keys.Action(e) {
+		case game.ActionRotateCW:
+			b.doRotate(1)
+		case game.ActionRotateCCW:
+			b.doRotate(-1)
+		case game.ActionDown:
+			b.doSoftDrop()
+		case game.ActionDrop:
+			b.doHardDrop()
+		case game.ActionHold:
+			b.doHold()
+		case game.ActionLeft:
+			b.doLeft()
+		case game.ActionRight:
+			b.doRight()
+		case game.ActionQuit:
+			b.runner.Quit()
+		}
+	case game.TickEvent:
+		if sec := e.Time.Unix(); sec != b.shown { // a frame is shorter than the second shown
+			b.shown = sec
+			b.msg = e.Time.Format("2006-01-02 03:04:05")
+		}
+		b.doFrame()
 	}
 }
 
-func (b *russiaBlock) Next() bool {
-	return !b.stop
+func (b *russiaBlock) Render(sc *game.Screen) {
+	game.DrawCentered(sc, b.layout())
 }
 
 func (b *russiaBlock) Finish() {
 }
 
-func (b *russiaBlock) run() {
-	tick := time.Tick(10 * time.Millisecond)
-	prev := time.Now()
-	b.draw()
-	for !b.stop {
-		select {
-		case op := <-b.opCh:
-			switch op {
-			case game.SysUp: // switch
-				b.doSwitch()
-			case game.SysDown:
-				b.doRapidDown()
-			case game.SysLeft:
-				b.doLeft()
-			case game.SysRight:
-				b.doRight()
-			}
-			b.draw()
-		case <-tick:
-			if time.Now().Add(-1 * time.Second).After(prev) {
-				prev = time.Now()
-				b.msg = time.Now().Format("2006-01-02 03:04:05")
-				b.doDown()
-				b.draw()
-			}
-		}
+// genNext fill the queue of the next blocks from the randomizer
+func (b *russiaBlock) genNext() {
+	for len(b.queue) < b.preview+1 {
+		b.queue = append(b.queue, b.randomizer.Next())
 	}
 }
 
-func (b *russiaBlock) genNext() {
-	idx, roll := rand.Intn(len(blocks)), rand.Intn(4)
-	bl := blocks[idx]
-	for i := 0; i < roll; i++ {
-		bl = bl.Switch()
+// spawn take the next block as the current one
+func (b *russiaBlock) spawn() {
+	b.genNext()
+	next := b.queue[0]
+	b.queue = b.queue[1:]
+	b.place(next)
+}
+
+// place put block `blk` centred at the top as the current one, the game is over if it does not fit
+func (b *russiaBlock) place(blk block) {
+	b.curr = blk
+	b.pos = game.Point{Y: (b.width - blk.Size()) / 2}
+	b.fall, b.lock, b.resets, b.lowest = 0, 0, 0, b.pos.X
+	if !b.isValid(b.pos, b.curr) {
+		b.msg = "Game over!"
+		b.runner.GameOver(game.OutcomeLose)
 	}
-	b.next = bl
 }
 
-func (b *russiaBlock) draw() {
-	game.Clear()
-	game.Cursor(game.Point{})
+// layout the panel, and the messages at right
+func (b *russiaBlock) layout() game.Widget {
+	title, border := b.theme.Style(game.RoleTitle), b.theme.Style(game.RoleBorder)
+
+	return game.HBox{Gap: 2, Children: []game.Widget{
+		game.Box{Title: "Hold", Style: border, Padding: 1, Child: game.Canvas{Rows: 2, Cols: 4, Paint: b.drawHold}},
+		game.Canvas{Rows: b.height + 2, Cols: b.width + 2, Paint: b.drawPanel},
+		game.Box{Title: "Next", Style: border, Padding: 1, Child: game.Canvas{Rows: 3*b.preview - 1, Cols: 4, Paint: b.drawNext}},
+		game.VBox{Children: []game.Widget{
+			game.ScoreBoard{Stats: []game.Stat{
+				{Name: "Score", Value: b.score},
+				{Name: "Lines", Value: b.lines},
+				{Name: "Level", Value: b.level()},
+				{Name: "Speed", Value: fmt.Sprintf("%.1f rows/s", float64(time.Second)/float64(time.Duration(b.gravity())*russiaBlockFrame))},
+			}, Style: title},
+			game.Box{Title: "Tips", Style: border, Padding: 1, Child: game.KeyHelp{Bindings: b.keys}},
+			game.Label{Text: b.msg, Width: 20},
+		}},
+	}}
+}
+
+// russiaBlockKeys the default bindings, see ~/.config/russia-block/keys.toml
+var russiaBlockKeys = game.Keymap{
+	{Action: game.ActionQuit, Keys: []game.Key{game.KeyRune('q')}, Help: "exit"},
+	{Action: game.ActionMenu, Keys: []game.Key{game.KeyCode(game.SysEsc)}, Help: "menu"},
+	{Action: game.ActionPause, Keys: []game.Key{game.KeyRune('p')}, Help: "pause"},
+	{Action: game.ActionLeft, Keys: []game.Key{game.KeyRune('a'), game.KeyCode(game.SysLeft)}, Help: "left"},
+	{Action: game.ActionRight, Keys: []game.Key{game.KeyRune('d'), game.KeyCode(game.SysRight)}, Help: "right"},
+	{Action: game.ActionRotateCW, Keys: []game.Key{game.KeyRune('w'), game.KeyCode(game.SysUp)}, Help: "rotate"},
+	{Action: game.ActionRotateCCW, Keys: []game.Key{game.KeyRune('z')}, Help: "rotate ccw"},
+	{Action: game.ActionDown, Keys: []game.Key{game.KeyRune('s'), game.KeyCode(game.SysDown)}, Help: "soft drop"},
+	{Action: game.ActionDrop, Keys: []game.Key{game.KeyRune(' ')}, Help: "hard drop"},
+	{Action: game.ActionHold, Keys: []game.Key{game.KeyRune('c')}, Help: "hold"},
+}
 
-	// panel
-	game.Draw(strings.Repeat(russiaBlockWall, b.width+2))
+func (b *russiaBlock) drawPanel(sc *game.Screen) {
+	wall := b.theme.Style(game.RoleWall)
+	sc.DrawString(game.Point{}, strings.Repeat(russiaBlockWall, b.width+2), wall)
 
 	for i, s := range b.runtime {
-		game.DrawAt(game.Point{X: i + 1}, russiaBlockWall)
+		p := sc.DrawString(game.Point{X: i + 1}, russiaBlockWall, wall)
 		for _, c := range s {
 			if c == 0 || c == ' ' {
-				game.Draw(russiaBlockEmpty)
+				p = sc.DrawString(p, russiaBlockEmpty, game.StyleDefault)
 			} else {
-				game.Draw(russiaBlockBlk)
+				p = sc.DrawString(p, russiaBlockBlk, b.style(c))
 			}
 		}
-		game.DrawLine(russiaBlockWall)
+		sc.DrawString(p, russiaBlockWall, wall)
 	}
-	game.Draw(strings.Repeat(russiaBlockWall, b.width+2))
+	sc.DrawString(game.Point{X: b.height + 1}, strings.Repeat(russiaBlockWall, b.width+2), wall)
 
+	style, ghost := b.style(b.curr.Kind()), b.ghost()
 	for _, p := range b.curr.Points() {
-		game.DrawAt(game.Point{X: b.pos.X + p.X + 1, Y: b.pos.Y + p.Y + 1}, russiaBlockBlk)
+		sc.DrawString(game.Point{X: ghost.X + p.X + 1, Y: ghost.Y + p.Y + 1}, russiaBlockBlk, style.Merge(b.theme.Style(game.RoleGhost)))
 	}
+	for _, p := range b.curr.Points() {
+		sc.DrawString(game.Point{X: b.pos.X + p.X + 1, Y: b.pos.Y + p.Y + 1}, russiaBlockBlk, style)
+	}
+}
 
-	// messages at right
-	game.DrawAt(game.Point{X: 1, Y: b.width + 4}, fmt.Sprintf("Score: %d", b.score))
-	game.DrawAt(game.Point{X: 2, Y: b.width + 4}, "Next:")
-	game.DrawAt(game.Point{X: 3, Y: b.width + 6}, b.next.GetLine(0))
-	game.DrawAt(game.Point{X: 4, Y: b.width + 6}, b.next.GetLine(1))
-	game.DrawAt(game.Point{X: 5, Y: b.width + 6}, b.next.GetLine(2))
-	game.DrawAt(game.Point{X: 6, Y: b.width + 6}, b.next.GetLine(3))
-	game.DrawAt(game.Point{X: 7, Y: b.width + 4}, "Tips:")
-	game.DrawAt(game.Point{X: 8, Y: b.width + 7}, "q -> exit")
-	game.DrawAt(game.Point{X: 9, Y: b.width + 7}, "a -> left")
-	game.DrawAt(game.Point{X: 10, Y: b.width + 7}, "d -> right")
-	game.DrawAt(game.Point{X: 11, Y: b.width + 7}, "w -> switch")
-	game.DrawAt(game.Point{X: 12, Y: b.width + 7}, "s -> down")
-	game.DrawAt(game.Point{X: 13, Y: b.width + 4}, sub(b.msg))
-	game.Cursor(game.Point{X: b.height + 1, Y: b.width + 3})
+// drawNext draw the next blocks of the preview from the top, three rows each
+func (b *russiaBlock) drawNext(sc *game.Screen) {
+	for i := 0; i < b.preview && i < len(b.queue); i++ {
+		b.drawBlock(sc, game.Point{X: 3 * i}, b.queue[i], b.style(b.queue[i].Kind()))
+	}
 }
 
-func (b *russiaBlock) doSwitch() {
-	s := b.curr.Switch()
-	if b.isValid(b.pos, s) {
-		b.curr = s
+// drawHold draw the held block, faint if already held since the current block spawned
+func (b *russiaBlock) drawHold(sc *game.Screen) {
+	if b.held.Kind() == 0 {
+		return
+	}
+	style := b.style(b.held.Kind())
+	if b.holdUsed {
+		style = style.Faint()
+	}
+	b.drawBlock(sc, game.Point{}, b.held, style)
+}
+
+// drawBlock draw block `blk` as spawned with its top row at `at`
+func (b *russiaBlock) drawBlock(sc *game.Screen, at game.Point, blk block, style game.Style) {
+	points, top := blk.Points(), blk.Size()
+	for _, p := range points {
+		if p.X < top {
+			top = p.X
+		}
+	}
+	for _, p := range points {
+		sc.DrawString(game.Point{X: at.X + p.X - top, Y: at.Y + p.Y}, russiaBlockBlk, style)
+	}
+}
+
+// style the style of the tetromino `kind` in the theme
+func (b *russiaBlock) style(kind byte) game.Style {
+	return b.theme.Style(game.RoleTetromino + "." + string(kind))
+}
+
+// doRotate rotate clockwise if `dir` > 0 else counter-clockwise, trying the SRS kicks in order
+func (b *russiaBlock) doRotate(dir int) {
+	r := b.curr.Rotate(dir)
+	for _, k := range b.curr.Kicks(dir) {
+		if pos := b.pos.Add(k); b.isValid(pos, r) {
+			b.curr, b.pos = r, pos
+			b.moved()
+			return
+		}
+	}
+}
+
+// doHold put the current block in the hold as spawned, and take the held one or the next,
+// once until the block is merged
+func (b *russiaBlock) doHold() {
+	if b.holdUsed {
+		return
+	}
+	held := b.held
+	b.held, b.holdUsed = block{kind: b.curr.Kind()}, true
+	if held.Kind() == 0 {
+		b.spawn()
+	} else {
+		b.place(held)
 	}
 }
 
@@ -178,68 +316,142 @@ func (b *russiaBlock) doRight() {
 	b.doMove(0, 1)
 }
 
-func (b *russiaBlock) doDown() bool {
-	b.doMove(1, 0)
-	return b.doCheck()
-}
-
-func (b *russiaBlock) doMove(x, y int) {
+func (b *russiaBlock) doMove(x, y int) bool {
 	target := b.pos.Add(game.Point{X: x, Y: y})
 
 	if b.isValid(target, b.curr) {
 		b.pos = target
+		b.moved()
+		return true
 	}
+	return false
 }
 
-func (b *russiaBlock) doRapidDown() {
-	// just do down
-	for i := 0; i < b.height; i++ {
-		if b.doDown() {
-			return
+// moved count a move of the block for the lock delay: reaching a new lowest row gives the resets back,
+// and else the move resets the lock delay while resets are left
+func (b *russiaBlock) moved() {
+	if b.pos.X > b.lowest {
+		b.lowest, b.resets, b.lock = b.pos.X, 0, 0
+	} else if b.resets < russiaBlockLockResets {
+		b.resets++
+		b.lock = 0
+	}
+}
+
+// doFrame advance a frame: the block falls a row every gravity frames,
+// and locks after resting on the stack for russiaBlockLockDelay frames
+func (b *russiaBlock) doFrame() {
+	if b.landed() {
+		if b.lock++; b.lock >= russiaBlockLockDelay {
+			b.doLock()
 		}
+		return
+	}
+
+	if b.fall++; b.fall >= b.gravity() {
+		b.fall = 0
+		b.doMove(1, 0)
 	}
 }
 
-func (b *russiaBlock) doCheck() (merged bool) {
-	down := b.pos.Add(game.Point{X: 1})
+// level the level from 1, advancing every russiaBlockLevelLines lines
+func (b *russiaBlock) level() int {
+	return 1 + b.lines/russiaBlockLevelLines
+}
 
-	if !b.isValid(down, b.curr) {
-		merged = true
-		lines := make([]int, 0)
-		for _, p := range b.curr.Points() {
-			// do merge
-			b.runtime[b.pos.X+p.X][b.pos.Y+p.Y] = 1
-			filled := true
-			for _, r := range b.runtime[b.pos.X+p.X] {
-				if r == 0 {
-					filled = false
-					break
-				}
-			}
-			if filled {
-				lines = append(lines, b.pos.X+p.X)
+// gravity the frames the block takes to fall a row at the level
+func (b *russiaBlock) gravity() int {
+	if l := b.level(); l < len(russiaBlockGravity) {
+		return russiaBlockGravity[l-1]
+	}
+	return russiaBlockGravity[len(russiaBlockGravity)-1]
+}
+
+// doSoftDrop move the block down a row, a point a row
+func (b *russiaBlock) doSoftDrop() {
+	if b.doMove(1, 0) {
+		b.fall = 0
+		b.addScore(1)
+	}
+}
+
+// doHardDrop drop the block to the ghost and lock it at once, two points a row
+func (b *russiaBlock) doHardDrop() {
+	ghost := b.ghost()
+	b.addScore(2 * (ghost.X - b.pos.X))
+	b.pos = ghost
+	b.doLock()
+}
+
+// ghost the position the block lands at, dropping straight down
+func (b *russiaBlock) ghost() game.Point {
+	pos := b.pos
+	for b.isValid(pos.Add(game.Point{X: 1}), b.curr) {
+		pos.X++
+	}
+	return pos
+}
+
+// landed whether the block rests on the stack or the floor
+func (b *russiaBlock) landed() bool {
+	return !b.isValid(b.pos.Add(game.Point{X: 1}), b.curr)
+}
+
+func (b *russiaBlock) addScore(n int) {
+	b.score += n
+	b.runner.SetScore(b.score)
+}
+
+// doLock merge the block into the stack, clear the filled lines and spawn the next block
+func (b *russiaBlock) doLock() {
+	lines := make([]int, 0)
+	for _, p := range b.curr.Points() {
+		// do merge
+		b.runtime[b.pos.X+p.X][b.pos.Y+p.Y] = b.curr.Kind()
+		filled := true
+		for _, r := range b.runtime[b.pos.X+p.X] {
+			if r == 0 {
+				filled = false
+				break
 			}
 		}
-		// do score check
-		if len(lines) > 0 {
-			b.score += 1 << (len(lines) - 1)
-			b.movePane(lines)
+		if filled {
+			lines = append(lines, b.pos.X+p.X)
 		}
+	}
+	// do score check
+	b.doScore(len(lines))
+	sort.Ints(lines) // from the top, the lines below stay in place
+	b.movePane(lines)
+
+	// generate new
+	b.holdUsed = false
+	b.spawn()
+}
 
-		// generate new
-		b.curr = b.next
-		b.pos = game.Point{Y: b.width / 2}
-		b.genNext()
-
-		// game over
-		if !b.isValid(b.pos, b.curr) {
-			b.msg = "Game over!"
-			b.draw()
-			time.Sleep(time.Second)
-			os.Exit(0)
+// doScore score `n` lines cleared by a lock as the guideline, times the level before the clear:
+// 1.5 times for a tetris back to back, and 50 points more for each lock of the combo before
+func (b *russiaBlock) doScore(n int) {
+	if n == 0 {
+		b.combo = -1
+		return
+	}
+
+	level := b.level()
+	points := russiaBlockLinePoints[n] * level
+	if n == 4 {
+		if b.b2b {
+			points = points * 3 / 2
 		}
+		b.b2b = true
+	} else {
+		b.b2b = false
 	}
-	return
+	b.combo++
+	points += 50 * b.combo * level
+
+	b.lines += n
+	b.addScore(points)
 }
 
 func (b *russiaBlock) isValid(pos game.Point, blk block) bool {
@@ -266,10 +478,3 @@ func (b *russiaBlock) movePane(lines []int) {
 		}
 	}
 }
-
-func sub(s string) string {
-	if len(s) < 20 {
-		return s
-	}
-	return s[:20]
-}
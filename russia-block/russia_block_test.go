@@ -0,0 +1,259 @@
+package main
+
+import (
+	"math/rand"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/zhaowk/game"
+	"github.com/zhaowk/game/gametest"
+)
+
+// newTestBlock a game of an empty 10x15 board, dealing from a 7-bag of seed 1
+func newTestBlock(t *testing.T) *russiaBlock {
+	t.Helper()
+	b := &russiaBlock{width: 10, height: 15, preview: 3, combo: -1, runner: game.NewRunner()}
+	b.runtime = make([][]byte, b.height)
+	for i := range b.runtime {
+		b.runtime[i] = make([]byte, b.width)
+	}
+	var err error
+	if b.randomizer, err = newRandomizer("7-bag", rand.New(rand.NewSource(1))); err != nil {
+		t.Fatal(err)
+	}
+	return b
+}
+
+func TestDraw(t *testing.T) {
+	b := newTestBlock(t)
+	b.keys, b.score, b.msg = russiaBlockKeys, 3, "Game over!"
+	copy(b.runtime[14], []byte("IIJ JJOOLL"))
+	b.curr, b.queue = blocks[5], []block{blocks[6], blocks[0], blocks[1]}
+	b.pos = game.Point{X: 3, Y: 4}
+	b.held, b.holdUsed = blocks[3], true
+
+	vt := gametest.Render(18, 64, func(sc *game.Screen) { game.DrawWidget(sc, game.Point{}, b.layout()) })
+	gametest.Golden(t, "testdata/draw.golden", vt.String())
+
+	if c := vt.Cell(5, 18); c.Rune != '@' || c.Attr.Fg != gametest.Indexed(5) {
+		t.Errorf("piece cell = %q %+v, want a magenta '@' of the T", c.Rune, c.Attr)
+	}
+	if c := vt.Cell(15, 19); c.Attr.Fg != gametest.Indexed(11) {
+		t.Errorf("settled O cell = %+v, want bright yellow", c.Attr)
+	}
+	if c := vt.Cell(13, 18); c.Rune != '@' || c.Attr.Fg != gametest.Indexed(5) || !c.Attr.Faint {
+		t.Errorf("ghost cell = %q %+v, want a faint magenta '@'", c.Rune, c.Attr)
+	}
+	if c := vt.Cell(1, 3); c.Rune != '@' || !c.Attr.Faint {
+		t.Errorf("held cell = %q %+v, want a faint '@' as already held", c.Rune, c.Attr)
+	}
+}
+
+func TestRotate(t *testing.T) {
+	tests := []struct {
+		blk  block
+		want []game.Point
+	}{
+		{block{kind: 'T', state: 1}, []game.Point{{X: 1, Y: 2}, {X: 0, Y: 1}, {X: 1, Y: 1}, {X: 2, Y: 1}}},
+		{block{kind: 'T', state: 2}, []game.Point{{X: 2, Y: 1}, {X: 1, Y: 2}, {X: 1, Y: 1}, {X: 1, Y: 0}}},
+		{block{kind: 'I', state: 1}, []game.Point{{X: 0, Y: 2}, {X: 1, Y: 2}, {X: 2, Y: 2}, {X: 3, Y: 2}}},
+		{block{kind: 'I', state: 3}, []game.Point{{X: 3, Y: 1}, {X: 2, Y: 1}, {X: 1, Y: 1}, {X: 0, Y: 1}}},
+		{block{kind: 'O', state: 1}, []game.Point{{X: 0, Y: 1}, {X: 1, Y: 1}, {X: 0, Y: 0}, {X: 1, Y: 0}}},
+	}
+	for _, tt := range tests {
+		if got := tt.blk.Points(); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%c in state %d = %v, want %v", tt.blk.kind, tt.blk.state, got, tt.want)
+		}
+	}
+
+	for _, blk := range blocks {
+		cw, ccw := blk, blk
+		for i := 0; i < 4; i++ {
+			cw, ccw = cw.Rotate(1), ccw.Rotate(-1)
+		}
+		if cw != blk || ccw != blk || blk.Rotate(1).Rotate(-1) != blk {
+			t.Errorf("%c not back to the spawn state", blk.kind)
+		}
+	}
+}
+
+func TestWallKick(t *testing.T) {
+	tests := []struct {
+		curr block
+		pos  game.Point
+		dir  int
+		want block
+		at   game.Point
+	}{
+		{block{kind: 'T'}, game.Point{X: 5, Y: 3}, 1, block{kind: 'T', state: 1}, game.Point{X: 5, Y: 3}},
+		{block{kind: 'T', state: 1}, game.Point{X: 5, Y: -1}, 1, block{kind: 'T', state: 2}, game.Point{X: 5, Y: 0}},
+		{block{kind: 'I', state: 1}, game.Point{X: 5, Y: -2}, -1, block{kind: 'I'}, game.Point{X: 5, Y: 0}},
+		{block{kind: 'I', state: 3}, game.Point{X: 5, Y: 8}, 1, block{kind: 'I'}, game.Point{X: 5, Y: 6}},
+		{block{kind: 'T'}, game.Point{X: 13, Y: 4}, 1, block{kind: 'T', state: 1}, game.Point{X: 12, Y: 3}},
+	}
+	for _, tt := range tests {
+		b := newTestBlock(t)
+		b.curr, b.pos = tt.curr, tt.pos
+		b.doRotate(tt.dir)
+		if b.curr != tt.want || b.pos != tt.at {
+			t.Errorf("rotate %c from %d at %v = %d at %v, want %d at %v",
+				tt.curr.kind, tt.curr.state, tt.pos, b.curr.state, b.pos, tt.want.state, tt.at)
+		}
+	}
+}
+
+func TestHold(t *testing.T) {
+	b := newTestBlock(t)
+	b.spawn()
+
+	first, next := b.curr, b.queue[0]
+	b.doRotate(1)
+	b.doMove(3, 0)
+	b.doHold()
+	if b.held != first || b.curr != next || b.pos.X != 0 {
+		t.Fatalf("hold = %v, current %v at %v, want %v held and %v spawned", b.held, b.curr, b.pos, first, next)
+	}
+
+	b.doHold()
+	if b.held != first || b.curr != next {
+		t.Errorf("held twice in a drop: hold %v, current %v", b.held, b.curr)
+	}
+
+	b.doHardDrop()
+	spawned := b.curr
+	b.doHold()
+	if b.held != spawned || b.curr != first || b.pos != (game.Point{Y: (b.width - first.Size()) / 2}) {
+		t.Errorf("after the drop: hold %v, current %v at %v, want %v held and %v spawned", b.held, b.curr, b.pos, spawned, first)
+	}
+}
+
+func TestDrop(t *testing.T) {
+	b := newTestBlock(t)
+	b.queue = []block{blocks[5]}
+	b.spawn()
+
+	b.doSoftDrop()
+	b.doSoftDrop()
+	if b.pos.X != 2 || b.score != 2 {
+		t.Errorf("soft dropped to %v scoring %d, want row 2 scoring 2", b.pos, b.score)
+	}
+	if g := b.ghost(); g != (game.Point{X: 13, Y: 3}) {
+		t.Errorf("ghost at %v, want {13 3}", g)
+	}
+
+	for i := 0; i < b.gravity(); i++ {
+		b.doFrame()
+	}
+	if b.pos.X != 3 {
+		t.Errorf("fell to %v in %d frames, want row 3", b.pos, b.gravity())
+	}
+
+	b.doHardDrop()
+	if b.score != 2+2*10 || string(b.runtime[14][3:6]) != "TTT" || b.pos.X != 0 {
+		t.Errorf("hard dropped scoring %d, bottom %q, next at %v", b.score, b.runtime[14], b.pos)
+	}
+}
+
+func TestLockDelay(t *testing.T) {
+	b := newTestBlock(t)
+	b.queue = []block{blocks[5]}
+	b.spawn()
+	for b.doMove(1, 0) {
+	}
+	landed := b.curr
+
+	// the moves reset the lock delay up to the limit
+	for i := 0; i < russiaBlockLockResets; i++ {
+		for j := 0; j < russiaBlockLockDelay-1; j++ {
+			b.doFrame()
+		}
+		b.doMove(0, 1-2*(i%2))
+	}
+	if b.curr != landed || b.pos.X != 13 {
+		t.Fatalf("locked while moving, current %v at %v", b.curr, b.pos)
+	}
+	for j := 0; j < russiaBlockLockDelay-1; j++ {
+		b.doFrame()
+	}
+	b.doMove(0, 1)
+	if b.pos.X != 13 {
+		t.Fatalf("locked with a reset left, current %v at %v", b.curr, b.pos)
+	}
+	b.doFrame()
+	if b.pos.X != 0 || b.runtime[13][6] != 'T' {
+		t.Errorf("not locked after %d resets, current %v at %v", russiaBlockLockResets, b.curr, b.pos)
+	}
+}
+
+func TestScore(t *testing.T) {
+	b := newTestBlock(t)
+	tests := []struct {
+		lines int
+		score int
+		level int
+	}{
+		{1, 100, 1},
+		{4, 100 + 800 + 50, 1},      // combo 1
+		{4, 950 + 1200 + 100, 1},    // back to back, combo 2
+		{0, 2250, 1},                // combo broken
+		{1, 2250 + 100, 2},          // back to back broken, 10 lines
+		{4, 2350 + 1600 + 2*50, 2},  // at level 2, combo 1
+		{2, 4050 + 600 + 2*100, 2},  // combo 2
+		{3, 4850 + 1000 + 2*150, 2}, // combo 3
+	}
+	for i, tt := range tests {
+		b.doScore(tt.lines)
+		if b.score != tt.score || b.level() != tt.level {
+			t.Errorf("#%d: %d lines scored %d at level %d, want %d at level %d", i, tt.lines, b.score, b.level(), tt.score, tt.level)
+		}
+	}
+	if b.lines != 19 || b.gravity() != 16 {
+		t.Errorf("%d lines, gravity %d, want 19 lines and 16 frames", b.lines, b.gravity())
+	}
+	b.lines = 200
+	if b.gravity() != 1 {
+		t.Errorf("gravity %d at level %d, want 1 frame", b.gravity(), b.level())
+	}
+}
+
+func TestClearLines(t *testing.T) {
+	b := newTestBlock(t)
+	copy(b.runtime[13], " JJJOOLLLT")
+	copy(b.runtime[14], " ZZSSOOTTT")
+	b.curr, b.pos = block{kind: 'I', state: 3}, game.Point{X: 11, Y: -1} // the cells from the bottom
+
+	b.doLock()
+	for i, r := range b.runtime {
+		want := make([]byte, b.width)
+		if i >= 13 {
+			want[0] = 'I'
+		}
+		if !reflect.DeepEqual(r, want) {
+			t.Errorf("line %d = %q, want %q", i, r, want)
+		}
+	}
+	if b.lines != 2 || b.score != 300 {
+		t.Errorf("%d lines scoring %d, want 2 scoring 300", b.lines, b.score)
+	}
+}
+
+func TestClockMsg(t *testing.T) {
+	b := newTestBlock(t)
+	b.spawn()
+	at := time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	b.Update(game.TickEvent{Time: at})
+	if b.msg != "2020-01-02 03:04:05" {
+		t.Fatalf("msg = %q", b.msg)
+	}
+	b.msg = "kept"
+	b.Update(game.TickEvent{Time: at.Add(900 * time.Millisecond)})
+	if b.msg != "kept" {
+		t.Errorf("msg = %q formatted again in the same second", b.msg)
+	}
+	b.Update(game.TickEvent{Time: at.Add(time.Second)})
+	if b.msg != "2020-01-02 03:04:06" {
+		t.Errorf("msg = %q in the next second", b.msg)
+	}
+}
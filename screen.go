@@ -0,0 +1,268 @@
+package game
+
+import (
+	"bytes"
+	"strconv"
+	"unicode/utf8"
+)
+
+// Cell a character on the screen. A wide character takes two cells, the right one has Rune 0.
+type Cell struct {
+	Rune  rune
+	Comb  string // the runes shown with Rune: combining marks, variation selectors, ZWJ sequences
+	Style Style
+}
+
+var blankCell = Cell{Rune: ' '}
+
+// Screen a double-buffered cell grid. Draw the frame with Set and DrawString,
+// then Flush sends only the cells changed since the previous frame.
+type Screen struct {
+	rows, cols int
+	cells      []Cell // the frame being drawn
+	prev       []Cell // the frame on the terminal
+	full       bool   // repaint everything on next flush
+	origin     Point  // added to the points of Set and Get
+	buf        bytes.Buffer
+	out        *Output // nil for the package level output
+}
+
+// NewScreen screen of `rows` x `cols`
+func NewScreen(rows, cols int) *Screen {
+	s := &Screen{}
+	s.Resize(rows, cols)
+	return s
+}
+
+// SetOutput flush to `o` instead of the package level output, the next Flush repaints the whole screen
+func (s *Screen) SetOutput(o *Output) {
+	s.out = o
+	s.Invalidate()
+}
+
+// Size rows and cols of the screen
+func (s *Screen) Size() (rows, cols int) {
+	return s.rows, s.cols
+}
+
+// Resize change the size, the next Flush repaints the whole screen
+func (s *Screen) Resize(rows, cols int) {
+	if rows < 0 {
+		rows = 0
+	}
+	if cols < 0 {
+		cols = 0
+	}
+
+	s.rows, s.cols = rows, cols
+	s.cells = make([]Cell, rows*cols)
+	s.prev = make([]Cell, rows*cols)
+	s.Clear()
+	s.Invalidate()
+}
+
+// Invalidate forget what is on the terminal, the next Flush repaints the whole screen
+func (s *Screen) Invalidate() {
+	s.full = true
+}
+
+// Clear fill the frame with blanks, and reset the origin
+func (s *Screen) Clear() {
+	for i := range s.cells {
+		s.cells[i] = blankCell
+	}
+	s.origin = Point{}
+}
+
+// SetOrigin draw with Point `p` as (0, 0), e.g. SetOrigin(s.Center(rows, cols)) to centre a layout
+func (s *Screen) SetOrigin(p Point) {
+	s.origin = p
+}
+
+// Origin the point drawn as (0, 0)
+func (s *Screen) Origin() Point {
+	return s.origin
+}
+
+// Center the left-top of an area of `rows` x `cols` in the middle of the screen,
+// (0, 0) on the axes where the area does not fit
+func (s *Screen) Center(rows, cols int) Point {
+	var p Point
+	if rows < s.rows {
+		p.X = (s.rows - rows) / 2
+	}
+	if cols < s.cols {
+		p.Y = (s.cols - cols) / 2
+	}
+	return p
+}
+
+// Contains whether `p` is on the screen, regardless of the origin
+func (s *Screen) Contains(p Point) bool {
+	return p.X >= 0 && p.X < s.rows && p.Y >= 0 && p.Y < s.cols
+}
+
+// Set put rune `r` with style `st` at Point `p` from the origin, (0, 0) is left-top (x => row, y => col).
+// A wide rune takes the cell on the right too. Points out of the screen and zero width runes are ignored.
+func (s *Screen) Set(p Point, r rune, st Style) {
+	s.put(p.Add(s.origin), Cell{Rune: r, Style: st}, RuneWidth(r))
+}
+
+// put cell `c` of `width` columns at the absolute Point `p`, blanking the other half of the wide cells it overlaps.
+// A wide cell without room on the row is put as a blank.
+func (s *Screen) put(p Point, c Cell, width int) {
+	if width <= 0 || !s.Contains(p) {
+		return
+	}
+	if width > 1 && p.Y+1 >= s.cols {
+		c, width = Cell{Rune: ' ', Style: c.Style}, 1
+	}
+
+	i := p.X*s.cols + p.Y
+	if s.cells[i].Rune == 0 && p.Y > 0 { // the right half of a wide cell
+		s.cells[i-1] = Cell{Rune: ' ', Style: s.cells[i-1].Style}
+	}
+	if end := p.Y + width; end < s.cols && s.cells[i+width].Rune == 0 { // the left half of a wide cell
+		s.cells[i+width] = Cell{Rune: ' ', Style: s.cells[i+width].Style}
+	}
+
+	s.cells[i] = c
+	if width > 1 {
+		s.cells[i+1] = Cell{Style: c.Style}
+	}
+}
+
+// Get the cell at Point `p` from the origin, Rune is 0 for the right half of a wide character
+func (s *Screen) Get(p Point) Cell {
+	if p = p.Add(s.origin); s.Contains(p) {
+		return s.cells[p.X*s.cols+p.Y]
+	}
+	return blankCell
+}
+
+// DrawString draw `str` from Point `p` towards the right, return the point after it.
+// Columns are counted by display width: wide characters take two cells, combining marks and ZWJ sequences
+// stay with their base
+func (s *Screen) DrawString(p Point, str string, st Style) Point {
+	for str != "" {
+		r, w, n := cluster(str)
+		if w > 0 {
+			_, size := utf8.DecodeRuneInString(str)
+			s.put(p.Add(s.origin), Cell{Rune: r, Comb: str[size:n], Style: st}, w)
+			p.Y += w
+		}
+		str = str[n:]
+	}
+	return p
+}
+
+// Fill fill `n` columns from Point `p` towards the right with rune `r`
+func (s *Screen) Fill(p Point, n int, r rune, st Style) {
+	w := RuneWidth(r)
+	if w <= 0 {
+		return
+	}
+	for i := 0; i+w <= n; i += w {
+		s.Set(p.Add(Point{Y: i}), r, st)
+	}
+}
+
+// TooSmall whether the screen is smaller than `rows` x `cols`, if so draw the standard
+// "terminal too small" overlay over the whole screen instead of the frame
+func (s *Screen) TooSmall(rows, cols int) bool {
+	if s.rows >= rows && s.cols >= cols {
+		return false
+	}
+
+	lines := []string{
+		"Terminal too small",
+		"please resize to " + strconv.Itoa(rows) + "x" + strconv.Itoa(cols),
+		"now " + strconv.Itoa(s.rows) + "x" + strconv.Itoa(s.cols),
+	}
+
+	s.Clear()
+	top := s.Center(len(lines), 0).X
+	for i, l := range lines {
+		if len(l) > s.cols {
+			l = l[:s.cols]
+		}
+		s.DrawString(Point{X: top + i, Y: s.Center(0, len(l)).Y}, l, StyleDefault)
+	}
+	return true
+}
+
+// Flush send the frame to the output in one write, only the changes since the previous frame
+func (s *Screen) Flush() error {
+	out := s.out
+	if out == nil {
+		out = std
+	}
+
+	s.render(out.profile)
+	_, err := out.Write(s.buf.Bytes())
+	return err
+}
+
+// render put the minimal cursor moves, sgr changes and runes into s.buf, with the colors of `profile`
+func (s *Screen) render(profile ColorProfile) {
+	s.buf.Reset()
+
+	if s.full {
+		s.buf.WriteString(SgrNormal + ClearAll)
+		for i := range s.prev {
+			s.prev[i] = blankCell
+		}
+	}
+
+	cursor := Point{X: -1, Y: -1} // unknown
+	style, styled := StyleDefault, s.full
+
+	for i, c := range s.cells {
+		if c == s.prev[i] {
+			continue
+		}
+		s.prev[i] = c
+		if c.Rune == 0 { // the right half, drawn with the left one
+			continue
+		}
+
+		p := Point{X: i / s.cols, Y: i % s.cols}
+		s.moveCursor(cursor, p)
+
+		if !styled || c.Style != style {
+			s.buf.WriteString(c.Style.Convert(profile).sgr())
+			style, styled = c.Style, true
+		}
+		s.buf.WriteRune(c.Rune)
+		s.buf.WriteString(c.Comb)
+
+		width := 1
+		if p.Y+1 < s.cols && s.cells[i+1].Rune == 0 {
+			width = 2
+		}
+		if cursor = p.Add(Point{Y: width}); cursor.Y >= s.cols { // pending wrap, position unknown
+			cursor = Point{X: -1, Y: -1}
+		}
+	}
+
+	if styled && style != StyleDefault {
+		s.buf.WriteString(SgrNormal)
+	}
+	s.full = false
+}
+
+// moveCursor move the cursor from `from` to `to` with the shortest sequence
+func (s *Screen) moveCursor(from, to Point) {
+	if from == to {
+		return
+	}
+
+	if from.X == to.X && from.Y >= 0 && to.Y > from.Y {
+		forward := _CSI + strconv.Itoa(to.Y-from.Y) + "C"
+		if pos := cursorPos(to.X+1, to.Y+1); len(forward) < len(pos) {
+			s.buf.WriteString(forward)
+			return
+		}
+	}
+	s.buf.WriteString(cursorPos(to.X+1, to.Y+1))
+}
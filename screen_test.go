@@ -0,0 +1,93 @@
+package game
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestScreenFlushDiff(t *testing.T) {
+	s := NewScreen(3, 10)
+	s.DrawString(Point{X: 1, Y: 2}, "abc", StyleDefault)
+
+	s.render(ColorsRGB)
+	if got, want := s.buf.String(), SgrNormal+ClearAll+cursorPos(2, 3)+"abc"; got != want {
+		t.Errorf("first frame = %q, want %q", got, want)
+	}
+
+	// same frame, nothing to send
+	s.render(ColorsRGB)
+	if s.buf.Len() != 0 {
+		t.Errorf("unchanged frame = %q, want nothing", s.buf.String())
+	}
+
+	// one cell changed, one styled cell added further on the row
+	red := Style{Fg: ColorRed.Color()}
+	s.Set(Point{X: 1, Y: 3}, 'x', StyleDefault)
+	s.Set(Point{X: 1, Y: 6}, '@', red)
+	s.render(ColorsRGB)
+	want := cursorPos(2, 4) + SgrNormal + "x" + _CSI + "2C" + Sgr("0;31") + "@" + SgrNormal
+	if got := s.buf.String(); got != want {
+		t.Errorf("diff frame = %q, want %q", got, want)
+	}
+
+	// cleared frame erases what was drawn
+	s.Clear()
+	s.render(ColorsRGB)
+	want = cursorPos(2, 3) + SgrNormal + "   " + _CSI + "1C" + " "
+	if got := s.buf.String(); got != want {
+		t.Errorf("cleared frame = %q, want %q", got, want)
+	}
+}
+
+func TestScreenBounds(t *testing.T) {
+	s := NewScreen(2, 2)
+	s.Set(Point{X: 2, Y: 0}, 'x', StyleDefault)
+	s.Set(Point{X: 0, Y: -1}, 'x', StyleDefault)
+	if end := s.DrawString(Point{X: 1, Y: 1}, "abc", StyleDefault); end != (Point{X: 1, Y: 4}) {
+		t.Errorf("DrawString end = %v", end)
+	}
+	if c := s.Get(Point{X: 1, Y: 1}); c.Rune != 'a' {
+		t.Errorf("cell = %v, want 'a'", c)
+	}
+	if c := s.Get(Point{X: 5, Y: 5}); c != blankCell {
+		t.Errorf("cell out of screen = %v", c)
+	}
+}
+
+func TestScreenSetOutput(t *testing.T) {
+	var buf bytes.Buffer
+	s := NewScreen(1, 3)
+	s.SetOutput(NewOutput(&buf))
+	s.DrawString(Point{}, "ok", StyleDefault)
+
+	if err := s.Flush(); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := buf.String(), SgrNormal+ClearAll+cursorPos(1, 1)+"ok"; got != want {
+		t.Errorf("flushed %q, want %q", got, want)
+	}
+}
+
+func TestScreenWide(t *testing.T) {
+	s := NewScreen(2, 6)
+	if end := s.DrawString(Point{}, "方块a", StyleDefault); end != (Point{Y: 5}) {
+		t.Errorf("end = %v, want (0, 5)", end)
+	}
+	if c := s.Get(Point{Y: 1}); c.Rune != 0 {
+		t.Errorf("right half = %q, want 0", c.Rune)
+	}
+
+	s.render(ColorsRGB)
+	if got, want := s.buf.String(), SgrNormal+ClearAll+cursorPos(1, 1)+"方块a"; got != want {
+		t.Errorf("first frame = %q, want %q", got, want)
+	}
+
+	// a narrow rune on the right half blanks the left one, a wide one at the end of the row does not fit
+	s.Set(Point{Y: 3}, 'x', StyleDefault)
+	s.DrawString(Point{X: 1, Y: 4}, "é🐍", StyleDefault)
+	s.render(ColorsRGB)
+	want := cursorPos(1, 3) + SgrNormal + " x" + cursorPos(2, 5) + "é"
+	if got := s.buf.String(); got != want {
+		t.Errorf("second frame = %q, want %q", got, want)
+	}
+}
@@ -2,7 +2,8 @@ module github.com/zhaowk/game/snake
 
 go 1.18
 
-require (
-	github.com/zhaowk/game v1.0.2
-	golang.org/x/sys v0.0.0-20220909162455-aba9fc2a8ff2 // indirect
-)
+require github.com/zhaowk/game v1.0.2
+
+require golang.org/x/sys v0.0.0-20220909162455-aba9fc2a8ff2 // indirect
+
+replace github.com/zhaowk/game => ../
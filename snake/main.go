@@ -1,7 +1,23 @@
 package main
 
-import "github.com/zhaowk/game"
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/zhaowk/game"
+)
 
 func main() {
-	game.RunGame(&snake{})
+	r := game.NewRunner()
+	r.SetName("snake")
+	r.Flags(flag.CommandLine)
+	flag.Parse()
+
+	res := r.Run(&snake{})
+	if res.Err != nil {
+		fmt.Fprintln(os.Stderr, res.Err)
+		os.Exit(1)
+	}
+	fmt.Fprintf(os.Stderr, "%v, score %d, seed %d\n", res.Outcome, res.Score, res.Seed)
 }
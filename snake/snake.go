@@ -2,20 +2,16 @@ package main
 
 import (
 	"container/list"
-	"fmt"
 	"github.com/zhaowk/game"
 	"math/rand"
-	"os"
-	"strings"
 	"time"
 )
 
 const (
-	snakeBlank = " "
-	snakeBody  = "#"
-	snakeHead  = "O"
-	snakeWall  = "@"
-	snakeFood  = "o"
+	snakeBody = '#'
+	snakeHead = 'O'
+	snakeWall = '@'
+	snakeFood = 'o'
 )
 
 type snake struct {
@@ -23,120 +19,124 @@ type snake struct {
 	height int
 	msg    string
 
-	opCh      chan int
+	runner    *game.Runner
+	rand      *rand.Rand
+	theme     *game.Theme
+	keys      game.Keymap
 	snake     *list.List
 	food      game.Point
 	direction int
 }
 
-func (s *snake) Init(...interface{}) error {
+func (s *snake) Init(r *game.Runner, _ ...interface{}) (err error) {
+	if s.keys, err = r.Keymap(snakeKeys); err != nil {
+		return err
+	}
 	s.width = 10
 	s.height = 10
-	rand.Seed(time.Now().UnixNano())
+	s.rand = r.Rand()
+	s.theme = r.Theme()
+	s.runner = r
+	s.reset()
+
+	r.SetMinSize(s.layout().Size())
+	r.EnableMenu(s.reset)
+	r.SetTick(time.Second)
+	return nil
+}
 
+// reset start a new game
+func (s *snake) reset() {
 	s.snake = list.New()
 	pos := game.Point{X: s.height / 2, Y: s.width / 2}
 	s.snake.PushFront(pos)
 	s.direction = game.SysLeft
+	s.msg = ""
 	s.genFood()
-
-	s.opCh = make(chan int)
-
-	go s.run()
-	return nil
+	s.runner.SetScore(s.snake.Len())
 }
 
-func (s *snake) Run(k int, _ string) {
-	switch k {
-	case 'w', 'W', game.SysUp:
-		s.opCh <- game.SysUp
-	case 's', 'S', game.SysDown:
-		s.opCh <- game.SysDown
-	case 'a', 'A', game.SysLeft:
-		s.opCh <- game.SysLeft
-	case 'd', 'D', game.SysRight:
-		s.opCh <- game.SysRight
-	case 'q', 'Q':
-		os.Exit(0)
+func (s *snake) Update(e game.Event) {
+	switch e := e.(type) {
+	case game.Key:
+		switch s.keys.Action(e) {
+		case game.ActionUp:
+			s.direction = game.SysUp
+		case game.ActionDown:
+			s.direction = game.SysDown
+		case game.ActionLeft:
+			s.direction = game.SysLeft
+		case game.ActionRight:
+			s.direction = game.SysRight
+		case game.ActionQuit:
+			s.runner.Quit()
+		}
+	case game.TickEvent:
+		s.msg = e.Time.Format("2006-01-02 03:04:05")
+		s.doMove()
 	}
 }
 
-func (s *snake) Next() bool {
-	return true
+func (s *snake) Render(sc *game.Screen) {
+	game.DrawCentered(sc, s.layout())
 }
 
 func (s *snake) Finish() {
 }
 
-func (s *snake) run() {
-	tick := time.Tick(10 * time.Millisecond)
-	prev := time.Now()
-	s.draw()
-	for {
-		select {
-		case op := <-s.opCh:
-			switch op {
-			case game.SysUp: // switch
-				s.direction = game.SysUp
-			case game.SysDown:
-				s.direction = game.SysDown
-			case game.SysLeft:
-				s.direction = game.SysLeft
-			case game.SysRight:
-				s.direction = game.SysRight
-			}
-		case <-tick:
-			if time.Now().Add(-1 * time.Second).After(prev) {
-				prev = time.Now()
-				s.msg = time.Now().Format("2006-01-02 03:04:05")
-				s.doMove()
-				s.draw()
-			}
-		}
+// layout the panel, and the messages at right
+func (s *snake) layout() game.Widget {
+	title := s.theme.Style(game.RoleTitle)
+	score := 0
+	if s.snake != nil {
+		score = s.snake.Len()
 	}
+
+	return game.HBox{Gap: 2, Children: []game.Widget{
+		game.Canvas{Rows: s.height + 2, Cols: s.width + 2, Paint: s.drawPanel},
+		game.VBox{Children: []game.Widget{
+			game.ScoreBoard{Stats: []game.Stat{{Name: "Score", Value: score}}, Style: title},
+			game.Box{Title: "Tips", Style: s.theme.Style(game.RoleBorder), Padding: 1, Child: game.KeyHelp{Bindings: s.keys}},
+			game.Label{Text: s.msg, Width: 20},
+		}},
+	}}
 }
 
-func (s *snake) draw() {
-	// clear && move to (0, 0)
-	game.Clear()
-	game.Cursor(game.Point{})
+// snakeKeys the default bindings, see ~/.config/snake/keys.toml
+var snakeKeys = game.Keymap{
+	{Action: game.ActionQuit, Keys: []game.Key{game.KeyRune('q')}, Help: "exit"},
+	{Action: game.ActionMenu, Keys: []game.Key{game.KeyCode(game.SysEsc)}, Help: "menu"},
+	{Action: game.ActionPause, Keys: []game.Key{game.KeyRune('p'), game.KeyRune(' ')}, Help: "pause"},
+	{Action: game.ActionLeft, Keys: []game.Key{game.KeyRune('a'), game.KeyCode(game.SysLeft)}, Help: "left"},
+	{Action: game.ActionRight, Keys: []game.Key{game.KeyRune('d'), game.KeyCode(game.SysRight)}, Help: "right"},
+	{Action: game.ActionUp, Keys: []game.Key{game.KeyRune('w'), game.KeyCode(game.SysUp)}, Help: "up"},
+	{Action: game.ActionDown, Keys: []game.Key{game.KeyRune('s'), game.KeyCode(game.SysDown)}, Help: "down"},
+}
 
+func (s *snake) drawPanel(sc *game.Screen) {
 	// panel
-	game.Draw(strings.Repeat(snakeWall, s.width+2))
+	wall := s.theme.Style(game.RoleWall)
+	sc.Fill(game.Point{}, s.width+2, snakeWall, wall)
 	for i := 0; i < s.height; i++ {
-		game.DrawAt(game.Point{X: i + 1}, snakeWall)
-		for j := 0; j < s.width; j++ {
-			game.Draw(snakeBlank)
-		}
-		game.DrawLine(snakeWall)
+		sc.Set(game.Point{X: i + 1}, snakeWall, wall)
+		sc.Set(game.Point{X: i + 1, Y: s.width + 1}, snakeWall, wall)
 	}
-	game.Draw(strings.Repeat(snakeWall, s.width+2))
+	sc.Fill(game.Point{X: s.height + 1}, s.width+2, snakeWall, wall)
 
 	// snake
 	for e := s.snake.Front(); e != nil; e = e.Next() {
 		if p, ok := e.Value.(game.Point); ok {
-			game.DrawAt(game.Point{X: p.X + 1, Y: p.Y + 1}, snakeBody)
+			sc.Set(game.Point{X: p.X + 1, Y: p.Y + 1}, snakeBody, s.theme.Style(game.RoleSnakeBody))
 		}
 	}
 
 	// snake head
 	if head, ok := s.snake.Front().Value.(game.Point); ok {
-		game.DrawAt(game.Point{X: head.X + 1, Y: head.Y + 1}, snakeHead)
+		sc.Set(game.Point{X: head.X + 1, Y: head.Y + 1}, snakeHead, s.theme.Style(game.RoleSnakeHead))
 	}
 
 	// snake food
-	game.DrawAt(game.Point{X: s.food.X + 1, Y: s.food.Y + 1}, snakeFood)
-
-	// messages at right
-	game.DrawAt(game.Point{X: 1, Y: s.width + 4}, fmt.Sprintf("Score: %d", s.snake.Len()))
-	game.DrawAt(game.Point{X: 2, Y: s.width + 4}, "Tips:")
-	game.DrawAt(game.Point{X: 3, Y: s.width + 7}, "q -> exit")
-	game.DrawAt(game.Point{X: 4, Y: s.width + 7}, "a -> left")
-	game.DrawAt(game.Point{X: 5, Y: s.width + 7}, "d -> right")
-	game.DrawAt(game.Point{X: 6, Y: s.width + 7}, "w -> up")
-	game.DrawAt(game.Point{X: 7, Y: s.width + 7}, "s -> down")
-	game.DrawAt(game.Point{X: 8, Y: s.width + 4}, sub(s.msg))
-	game.Cursor(game.Point{X: s.height + 1, Y: s.width + 3})
+	sc.Set(game.Point{X: s.food.X + 1, Y: s.food.Y + 1}, snakeFood, s.theme.Style(game.RoleSnakeFood))
 }
 
 func (s *snake) doMove() {
@@ -161,16 +161,18 @@ func (s *snake) doMove() {
 
 		if !s.check(q) { // game over
 			s.msg = "Game over!"
-			s.draw()
-			time.Sleep(time.Second)
-			os.Exit(0)
+			s.runner.GameOver(game.OutcomeLose)
+			return
 		}
 
 		s.snake.PushFront(q)
 
 		if s.food == q {
+			s.runner.SetScore(s.snake.Len())
 			// check
-			s.doCheck()
+			if s.doCheck() {
+				return
+			}
 			// eat food, gen new
 			s.genFood()
 		} else {
@@ -180,17 +182,17 @@ func (s *snake) doMove() {
 	}
 }
 
-func (s *snake) doCheck() {
+func (s *snake) doCheck() (win bool) {
 	if s.snake.Len() == s.height*s.width {
 		s.msg = "Win!"
-		s.draw()
-		time.Sleep(time.Second)
-		os.Exit(0)
+		s.runner.GameOver(game.OutcomeWin)
+		return true
 	}
+	return false
 }
 
 func (s *snake) genFood() {
-	n := rand.Intn(s.height*s.width - s.snake.Len())
+	n := s.rand.Intn(s.height*s.width - s.snake.Len())
 	c := 0
 	for i := 0; i < n && c < s.height*s.width; c++ {
 		if s.isValid(c) {
@@ -224,10 +226,3 @@ func (s *snake) check(p game.Point) bool {
 	}
 	return true
 }
-
-func sub(s string) string {
-	if len(s) < 20 {
-		return s
-	}
-	return s[:20]
-}
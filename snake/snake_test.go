@@ -0,0 +1,57 @@
+package main
+
+import (
+	"container/list"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/zhaowk/game"
+	"github.com/zhaowk/game/gametest"
+)
+
+func TestDraw(t *testing.T) {
+	s := &snake{width: 10, height: 10, keys: snakeKeys, snake: list.New(), food: game.Point{X: 2, Y: 7}, msg: "Game over!"}
+	s.snake.PushBack(game.Point{X: 5, Y: 5})
+	s.snake.PushBack(game.Point{X: 5, Y: 6})
+	s.snake.PushBack(game.Point{X: 6, Y: 6})
+
+	vt := gametest.Render(12, 34, func(sc *game.Screen) { game.DrawWidget(sc, game.Point{}, s.layout()) })
+	gametest.Golden(t, "testdata/draw.golden", vt.String())
+
+	if c := vt.Cell(6, 6); c.Rune != snakeHead || c.Attr.Fg != gametest.Indexed(10) || !c.Attr.Bold {
+		t.Errorf("head cell = %q %+v, want a bold bright green %q", c.Rune, c.Attr, snakeHead)
+	}
+	if c := vt.Cell(3, 8); c.Rune != snakeFood {
+		t.Errorf("food cell = %q, want %q", c.Rune, snakeFood)
+	}
+}
+
+func TestPlay(t *testing.T) {
+	s := &snake{}
+	h := gametest.Start(t, s, 12, 34)
+
+	again := &snake{}
+	gametest.Start(t, again, 12, 34)
+	if again.food != s.food {
+		t.Fatalf("food = %v, then %v with the same seed", s.food, again.food)
+	}
+
+	h.Type("\rw") // new game from the start menu, then up
+	h.Ticks(5)
+	if got := h.VT.Cell(1, 6).Rune; got != snakeHead {
+		t.Fatalf("head cell = %q after 5 moves up", got)
+	}
+
+	h.Ticks(1) // into the wall
+	if got := h.VT.String(); !strings.Contains(got, "play again? (y/n)") {
+		t.Fatalf("screen:\n%s\nwant the play again dialog", got)
+	}
+	h.Type("n")
+	if h.Runner.Running() {
+		t.Fatal("game still running after refusing to play again")
+	}
+	if res := h.Stop(); res.Outcome != game.OutcomeLose || res.Score != 1 || res.Duration != 6*time.Second {
+		t.Errorf("result = %+v", res)
+	}
+}
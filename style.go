@@ -0,0 +1,216 @@
+package game
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// ColorMode how a Color is given
+type ColorMode uint8
+
+const (
+	ColorModeDefault ColorMode = iota // the terminal default
+	ColorMode8                        // 0-7 for ColorBlack, ..., ColorWhite, 8-15 for the bright ones
+	ColorMode256
+	ColorModeRGB
+)
+
+// Color a foreground or background color of a Style, the zero value is the terminal default
+type Color struct {
+	Mode  ColorMode
+	Value uint32 // index, or 0xRRGGBB
+}
+
+// ColorDefault the terminal default color
+var ColorDefault = Color{}
+
+// Color the color of 8 colors
+func (c Color8) Color() Color {
+	return Color{Mode: ColorMode8, Value: uint32(c)}
+}
+
+// Bright the bright version of the color
+func (c Color8) Bright() Color {
+	return Color{Mode: ColorMode8, Value: uint32(c) + 8}
+}
+
+// Color the true color
+func (r RGB) Color() Color {
+	return Color{Mode: ColorModeRGB, Value: uint32(r[0])<<16 | uint32(r[1])<<8 | uint32(r[2])}
+}
+
+// Color256 color `n` of the 256 colors
+func Color256(n uint8) Color {
+	return Color{Mode: ColorMode256, Value: uint32(n)}
+}
+
+// RGB the red, green and blue of a true color
+func (c Color) RGB() RGB {
+	return RGB{uint8(c.Value >> 16), uint8(c.Value >> 8), uint8(c.Value)}
+}
+
+// sgr the sgr parameters of the color as foreground or background, empty for the default
+func (c Color) sgr(t ColorType) string {
+	switch c.Mode {
+	case ColorMode8:
+		if c.Value >= 8 {
+			return SgrColor(t+BrightForeground, Color8(c.Value-8))
+		}
+		return SgrColor(t, Color8(c.Value))
+	case ColorMode256:
+		return SgrColor8bit(t, uint8(c.Value))
+	case ColorModeRGB:
+		return SgrColorRGB(t, c.RGB())
+	}
+	return ""
+}
+
+var colorNames = []string{"black", "red", "green", "yellow", "blue", "magenta", "cyan", "white"}
+
+// String name of the color, as ParseColor accepts: "default", "red", "bright-red", "208" or "#ff8700"
+func (c Color) String() string {
+	switch c.Mode {
+	case ColorMode8:
+		if c.Value >= 8 {
+			return "bright-" + colorNames[c.Value%8]
+		}
+		return colorNames[c.Value%8]
+	case ColorMode256:
+		return strconv.Itoa(int(c.Value))
+	case ColorModeRGB:
+		return fmt.Sprintf("#%06x", c.Value)
+	}
+	return "default"
+}
+
+// ParseColor parse a color name: "default" or "", one of the 8 colors "red", "bright-red",
+// a 256 color index "208", or a true color "#ff8700"
+func ParseColor(s string) (Color, error) {
+	name := strings.ToLower(strings.TrimSpace(s))
+	switch {
+	case name == "" || name == "default":
+		return ColorDefault, nil
+	case strings.HasPrefix(name, "#"):
+		v, err := strconv.ParseUint(name[1:], 16, 32)
+		if err != nil || len(name) != 7 {
+			return ColorDefault, fmt.Errorf("bad rgb color %q", s)
+		}
+		return Color{Mode: ColorModeRGB, Value: uint32(v)}, nil
+	case name[0] >= '0' && name[0] <= '9':
+		v, err := strconv.ParseUint(name, 10, 8)
+		if err != nil {
+			return ColorDefault, fmt.Errorf("bad 256 color %q", s)
+		}
+		return Color256(uint8(v)), nil
+	}
+
+	bright := strings.HasPrefix(name, "bright-")
+	name = strings.TrimPrefix(name, "bright-")
+	for i, n := range colorNames {
+		if n == name {
+			if bright {
+				return Color8(i).Bright(), nil
+			}
+			return Color8(i).Color(), nil
+		}
+	}
+	return ColorDefault, fmt.Errorf("unknown color %q", s)
+}
+
+// MarshalText color name
+func (c Color) MarshalText() ([]byte, error) {
+	return []byte(c.String()), nil
+}
+
+// UnmarshalText parse color name
+func (c *Color) UnmarshalText(b []byte) (err error) {
+	*c, err = ParseColor(string(b))
+	return
+}
+
+// Attr text attributes of a Style
+type Attr uint16
+
+const (
+	AttrBold Attr = 1 << iota
+	AttrFaint
+	AttrItalic
+	AttrUnderline
+	AttrBlink
+	AttrReverse
+	AttrHidden
+	AttrStrike
+)
+
+var attrSgr = []string{SgrBold, SgrFaint, SgrItalic, SgrUnderLine, SgrSlowBlink, SgrReverse, SgrHide, SgrStrike}
+
+// Style the look of a cell: foreground, background and text attributes. The zero value is the terminal default.
+type Style struct {
+	Fg, Bg Color
+	Attr   Attr
+}
+
+// StyleDefault the terminal default style
+var StyleDefault = Style{}
+
+// Merge style `o` over `s`: the colors of `o` replace those of `s` unless default, the attributes add up
+func (s Style) Merge(o Style) Style {
+	if o.Fg != ColorDefault {
+		s.Fg = o.Fg
+	}
+	if o.Bg != ColorDefault {
+		s.Bg = o.Bg
+	}
+	s.Attr |= o.Attr
+	return s
+}
+
+// Bold the style in bold
+func (s Style) Bold() Style {
+	s.Attr |= AttrBold
+	return s
+}
+
+// Faint the style dimmed
+func (s Style) Faint() Style {
+	s.Attr |= AttrFaint
+	return s
+}
+
+// Underline the style underlined
+func (s Style) Underline() Style {
+	s.Attr |= AttrUnderline
+	return s
+}
+
+// Reverse the style with foreground and background swapped
+func (s Style) Reverse() Style {
+	s.Attr |= AttrReverse
+	return s
+}
+
+// Sgr the sgr parameters of the style joined by `;`, empty for the default
+func (s Style) Sgr() string {
+	var params []string
+	for i, p := range attrSgr {
+		if s.Attr&(1<<i) != 0 {
+			params = append(params, p)
+		}
+	}
+	if fg := s.Fg.sgr(Foreground); fg != "" {
+		params = append(params, fg)
+	}
+	if bg := s.Bg.sgr(Background); bg != "" {
+		params = append(params, bg)
+	}
+	return strings.Join(params, ";")
+}
+
+// sgr the sequence switching to the style from any other
+func (s Style) sgr() string {
+	if p := s.Sgr(); p != "" {
+		return Sgr("0;" + p)
+	}
+	return SgrNormal
+}
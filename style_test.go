@@ -0,0 +1,91 @@
+package game
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestStyleSgr(t *testing.T) {
+	tests := []struct {
+		style Style
+		want  string
+	}{
+		{StyleDefault, ""},
+		{Style{Fg: ColorRed.Color()}, "31"},
+		{Style{Fg: ColorRed.Bright(), Bg: ColorBlue.Bright()}, "91;104"},
+		{Style{Fg: Color256(208), Attr: AttrBold | AttrUnderline}, "1;4;38;5;208"},
+		{Style{Bg: RGB{1, 2, 3}.Color(), Attr: AttrFaint}, "2;48;2;1;2;3"},
+	}
+
+	for _, tt := range tests {
+		if got := tt.style.Sgr(); got != tt.want {
+			t.Errorf("%+v.Sgr() = %q, want %q", tt.style, got, tt.want)
+		}
+	}
+}
+
+func TestStyleMerge(t *testing.T) {
+	base := Style{Fg: ColorCyan.Color(), Bg: ColorBlack.Color(), Attr: AttrBold}
+	got := base.Merge(Style{Bg: Color256(236), Attr: AttrFaint})
+
+	want := Style{Fg: ColorCyan.Color(), Bg: Color256(236), Attr: AttrBold | AttrFaint}
+	if got != want {
+		t.Errorf("merge = %+v, want %+v", got, want)
+	}
+}
+
+func TestParseColor(t *testing.T) {
+	for _, s := range []string{"default", "red", "bright-white", "208", "#ff8700"} {
+		c, err := ParseColor(s)
+		if err != nil || c.String() != s {
+			t.Errorf("ParseColor(%q) = %v, %v", s, c, err)
+		}
+	}
+
+	for _, s := range []string{"purple", "256", "#fff", "#gggggg"} {
+		if _, err := ParseColor(s); err == nil {
+			t.Errorf("ParseColor(%q) no error", s)
+		}
+	}
+}
+
+func TestTheme(t *testing.T) {
+	th, err := ParseTheme([]byte(`{
+		"name": "test",
+		"styles": {
+			"wall": {"fg": "#444444", "bg": "black"},
+			"tile": {"fg": "white", "bold": true, "reverse": true}
+		}
+	}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got, want := th.Style(RoleWall), (Style{Fg: RGB{0x44, 0x44, 0x44}.Color(), Bg: ColorBlack.Color()}); got != want {
+		t.Errorf("wall = %+v, want %+v", got, want)
+	}
+	if got, want := th.Style(RoleTile+".4096"), (Style{Fg: ColorWhite.Color(), Attr: AttrBold | AttrReverse}); got != want {
+		t.Errorf("tile.4096 = %+v, want the tile style %+v", got, want)
+	}
+	if got, want := th.Style(RoleSnakeHead), DefaultTheme.Style(RoleSnakeHead); got != want {
+		t.Errorf("snake.head = %+v, want the default %+v", got, want)
+	}
+	if got := th.Style("unknown.role"); got != StyleDefault {
+		t.Errorf("unknown role = %+v", got)
+	}
+
+	// styles round trip
+	data, err := json.Marshal(th)
+	if err != nil {
+		t.Fatal(err)
+	}
+	again, err := ParseTheme(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for role, st := range th.Styles {
+		if again.Styles[role] != st {
+			t.Errorf("%s = %+v after round trip, want %+v", role, again.Styles[role], st)
+		}
+	}
+}
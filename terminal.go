@@ -0,0 +1,247 @@
+//go:build linux || darwin
+
+package game
+
+import (
+	"os"
+	"os/signal"
+	"sync"
+
+	"golang.org/x/sys/unix"
+)
+
+const (
+	keyBufferSize = 64
+	readBufSize   = 128
+)
+
+// xterm mouse tracking: button press/release (1000), drag (1002), sgr reports (1006)
+const (
+	mouseOn  = _CSI + "?1000h" + _CSI + "?1002h" + _CSI + "?1006h"
+	mouseOff = _CSI + "?1006l" + _CSI + "?1002l" + _CSI + "?1000l"
+)
+
+// xterm focus reporting (1004)
+const (
+	focusOn  = _CSI + "?1004h"
+	focusOff = _CSI + "?1004l"
+)
+
+// Terminal a tty session, kept in raw mode from OpenTerminal until Close
+type Terminal struct {
+	in       int
+	original *unix.Termios
+
+	mu    sync.Mutex
+	out   *Output // where the mode sequences go, std by default
+	mouse bool
+	focus bool
+
+	keys chan Key
+	sigs chan os.Signal
+	done chan struct{}
+	wg   sync.WaitGroup
+	once sync.Once
+	err  error
+}
+
+// OpenTerminal open /dev/tty and switch it into raw mode for the whole session.
+// The original termios is restored by Close, and on SIGINT/SIGTERM which also closes Keys.
+func OpenTerminal() (*Terminal, error) {
+	in, err := unix.Open("/dev/tty", unix.O_RDONLY, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	original, err := unix.IoctlGetTermios(in, tcGetRequest)
+	if err != nil {
+		_ = unix.Close(in)
+		return nil, err
+	}
+
+	tios := *original
+	tios.Iflag &^= brkInt | ixon
+	tios.Lflag &^= echo | icanon | isig | iexten
+	tios.Cflag &^= cSize | parenb
+	tios.Cflag |= cs8
+	// read returns after 100ms without input, so the reader can notice Close
+	tios.Cc[vmin] = 0
+	tios.Cc[vtime] = 1
+
+	if err = unix.IoctlSetTermios(in, tcSetRequest, &tios); err != nil {
+		_ = unix.Close(in)
+		return nil, err
+	}
+
+	t := &Terminal{
+		in:       in,
+		original: original,
+		keys:     make(chan Key, keyBufferSize),
+		sigs:     make(chan os.Signal, 1),
+		done:     make(chan struct{}),
+	}
+
+	signal.Notify(t.sigs, unix.SIGINT, unix.SIGTERM)
+	go t.watch()
+
+	t.wg.Add(1)
+	go t.read()
+	return t, nil
+}
+
+// Keys key events read from the terminal, closed after Close
+func (t *Terminal) Keys() <-chan Key {
+	return t.keys
+}
+
+// SetOutput send the sequences of the mouse and focus modes to `o` instead of std
+func (t *Terminal) SetOutput(o *Output) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.out = o
+}
+
+func (t *Terminal) output() *Output {
+	if t.out != nil {
+		return t.out
+	}
+	return std
+}
+
+// EnableMouse turn on mouse tracking, reports arrive on Keys with Code SysMouse
+func (t *Terminal) EnableMouse() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if !t.mouse {
+		t.output().Draw(mouseOn)
+		t.mouse = true
+	}
+}
+
+// DisableMouse turn off mouse tracking
+func (t *Terminal) DisableMouse() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.mouse {
+		t.output().Draw(mouseOff)
+		t.mouse = false
+	}
+}
+
+// EnableFocus turn on focus reporting, reports arrive on Keys with Code SysFocusIn and SysFocusOut
+func (t *Terminal) EnableFocus() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if !t.focus {
+		t.output().Draw(focusOn)
+		t.focus = true
+	}
+}
+
+// DisableFocus turn off focus reporting
+func (t *Terminal) DisableFocus() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.focus {
+		t.output().Draw(focusOff)
+		t.focus = false
+	}
+}
+
+// Close turn off the mouse and focus modes, stop reading and restore the original termios,
+// safe to call more than once
+func (t *Terminal) Close() error {
+	t.DisableMouse()
+	t.DisableFocus()
+	return t.restore()
+}
+
+// restore stop reading and restore the original termios, without any output as on a signal
+// the output may be busy on another goroutine. The modes are left to the owner of the output.
+func (t *Terminal) restore() error {
+	t.once.Do(func() {
+		close(t.done)
+		t.wg.Wait()
+		signal.Stop(t.sigs)
+
+		t.err = unix.IoctlSetTermios(t.in, tcSetRequest, t.original)
+		if err := unix.Close(t.in); t.err == nil {
+			t.err = err
+		}
+	})
+	return t.err
+}
+
+// Size rows and cols of the terminal window, 0 if unknown
+func (t *Terminal) Size() (rows, cols int) {
+	return winsize(t.in)
+}
+
+// Size rows and cols of the terminal window of stdout, or of stdin if stdout is redirected, 0 if unknown
+func Size() (rows, cols int) {
+	for _, f := range []*os.File{os.Stdout, os.Stdin} {
+		if rows, cols = winsize(int(f.Fd())); rows > 0 && cols > 0 {
+			return
+		}
+	}
+	return 0, 0
+}
+
+// winsize query the window size of the terminal `fd` by TIOCGWINSZ
+func winsize(fd int) (rows, cols int) {
+	ws, err := unix.IoctlGetWinsize(fd, unix.TIOCGWINSZ)
+	if err != nil {
+		return 0, 0
+	}
+	return int(ws.Row), int(ws.Col)
+}
+
+func (t *Terminal) watch() {
+	select {
+	case <-t.sigs:
+		_ = t.restore()
+	case <-t.done:
+	}
+}
+
+func (t *Terminal) read() {
+	defer t.wg.Done()
+	defer close(t.keys)
+
+	var d Decoder
+	buf := make([]byte, readBufSize)
+	for {
+		select {
+		case <-t.done:
+			return
+		default:
+		}
+
+		n, err := unix.Read(t.in, buf)
+		if err == unix.EINTR || err == unix.EAGAIN {
+			continue
+		} else if err != nil {
+			return
+		}
+
+		var keys []Key
+		if n > 0 {
+			keys = d.Feed(buf[:n])
+		} else if d.Pending() { // timeout, a lone ESC or a broken sequence
+			keys = d.Flush()
+		}
+
+		for _, k := range keys {
+			select {
+			case t.keys <- k:
+			case <-t.done:
+				return
+			}
+		}
+	}
+}
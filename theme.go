@@ -0,0 +1,182 @@
+package game
+
+import (
+	"encoding/json"
+	"os"
+	"strings"
+)
+
+// roles of the theme, a role `a.b` falls back to `a` when not in the theme
+const (
+	RoleText   = "text"
+	RoleTitle  = "title"
+	RoleWall   = "wall"
+	RoleBorder = "border"
+
+	RoleSnakeHead = "snake.head"
+	RoleSnakeBody = "snake.body"
+	RoleSnakeFood = "snake.food"
+
+	RoleTetromino = "tetromino" // RoleTetromino + ".I", ".O", ".T", ".S", ".Z", ".J", ".L"
+	RoleGhost     = "ghost"     // merged over the tetromino of the ghost piece
+
+	RoleTile = "tile" // RoleTile + ".2", ".4", ..., ".2048"
+
+	RoleBox       = "box"
+	RoleBoxTarget = "box.target" // a box on a target
+	RoleTarget    = "target"
+	RolePlayer    = "player"
+)
+
+// Theme the styles of the roles, so a game can be re-skinned without editing code
+type Theme struct {
+	Name   string           `json:"name"`
+	Styles map[string]Style `json:"styles"`
+}
+
+// DefaultTheme the theme of the games unless another is loaded
+var DefaultTheme = &Theme{
+	Name: "default",
+	Styles: map[string]Style{
+		RoleTitle:  StyleDefault.Bold(),
+		RoleWall:   {Fg: ColorWhite.Color(), Attr: AttrFaint},
+		RoleBorder: {Fg: ColorWhite.Color()},
+
+		RoleSnakeHead: {Fg: ColorGreen.Bright(), Attr: AttrBold},
+		RoleSnakeBody: {Fg: ColorGreen.Color()},
+		RoleSnakeFood: {Fg: ColorRed.Bright(), Attr: AttrBold},
+
+		RoleTetromino + ".I": {Fg: ColorCyan.Bright()},
+		RoleTetromino + ".O": {Fg: ColorYellow.Bright()},
+		RoleTetromino + ".T": {Fg: ColorMagenta.Color()},
+		RoleTetromino + ".S": {Fg: ColorGreen.Bright()},
+		RoleTetromino + ".Z": {Fg: ColorRed.Bright()},
+		RoleTetromino + ".J": {Fg: ColorBlue.Bright()},
+		RoleTetromino + ".L": {Fg: ColorYellow.Color()},
+		RoleGhost:            {Attr: AttrFaint},
+
+		RoleTile:           {Fg: ColorWhite.Bright(), Attr: AttrBold},
+		RoleTile + ".2":    {Fg: RGB{0x77, 0x6e, 0x65}.Color(), Bg: RGB{0xee, 0xe4, 0xda}.Color()},
+		RoleTile + ".4":    {Fg: RGB{0x77, 0x6e, 0x65}.Color(), Bg: RGB{0xed, 0xe0, 0xc8}.Color()},
+		RoleTile + ".8":    {Fg: RGB{0xf9, 0xf6, 0xf2}.Color(), Bg: RGB{0xf2, 0xb1, 0x79}.Color()},
+		RoleTile + ".16":   {Fg: RGB{0xf9, 0xf6, 0xf2}.Color(), Bg: RGB{0xf5, 0x95, 0x63}.Color()},
+		RoleTile + ".32":   {Fg: RGB{0xf9, 0xf6, 0xf2}.Color(), Bg: RGB{0xf6, 0x7c, 0x5f}.Color()},
+		RoleTile + ".64":   {Fg: RGB{0xf9, 0xf6, 0xf2}.Color(), Bg: RGB{0xf6, 0x5e, 0x3b}.Color()},
+		RoleTile + ".128":  {Fg: RGB{0xf9, 0xf6, 0xf2}.Color(), Bg: RGB{0xed, 0xcf, 0x72}.Color()},
+		RoleTile + ".256":  {Fg: RGB{0xf9, 0xf6, 0xf2}.Color(), Bg: RGB{0xed, 0xcc, 0x61}.Color()},
+		RoleTile + ".512":  {Fg: RGB{0xf9, 0xf6, 0xf2}.Color(), Bg: RGB{0xed, 0xc8, 0x50}.Color()},
+		RoleTile + ".1024": {Fg: RGB{0xf9, 0xf6, 0xf2}.Color(), Bg: RGB{0xed, 0xc5, 0x3f}.Color()},
+		RoleTile + ".2048": {Fg: RGB{0xf9, 0xf6, 0xf2}.Color(), Bg: RGB{0xed, 0xc2, 0x2e}.Color(), Attr: AttrBold},
+
+		RoleBox:       {Fg: ColorYellow.Color()},
+		RoleBoxTarget: {Fg: ColorGreen.Bright(), Attr: AttrBold},
+		RoleTarget:    {Fg: ColorRed.Color()},
+		RolePlayer:    {Fg: ColorCyan.Bright(), Attr: AttrBold},
+	},
+}
+
+// Style the style of `role`, or of its parent role `a` for `a.b`, StyleDefault if none.
+// A nil theme is the DefaultTheme.
+func (t *Theme) Style(role string) Style {
+	if t == nil {
+		t = DefaultTheme
+	}
+
+	for {
+		if st, ok := t.Styles[role]; ok {
+			return st
+		}
+		i := strings.LastIndexByte(role, '.')
+		if i < 0 {
+			return StyleDefault
+		}
+		role = role[:i]
+	}
+}
+
+// Merge theme `o` over `t`: the styles of `o` replace those of `t`, the others are kept
+func (t *Theme) Merge(o *Theme) *Theme {
+	m := &Theme{Name: o.Name, Styles: make(map[string]Style, len(t.Styles)+len(o.Styles))}
+	for role, st := range t.Styles {
+		m.Styles[role] = st
+	}
+	for role, st := range o.Styles {
+		m.Styles[role] = st
+	}
+	return m
+}
+
+// ParseTheme parse a theme in JSON, the roles not given keep the style of the DefaultTheme:
+//
+//	{"name": "dark", "styles": {"wall": {"fg": "#444444"}, "snake.head": {"fg": "bright-green", "bold": true}}}
+func ParseTheme(data []byte) (*Theme, error) {
+	var t Theme
+	if err := json.Unmarshal(data, &t); err != nil {
+		return nil, err
+	}
+	return DefaultTheme.Merge(&t), nil
+}
+
+// LoadTheme load a theme from the JSON file `path`, see ParseTheme
+func LoadTheme(path string) (*Theme, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return ParseTheme(data)
+}
+
+// styleJSON a Style in a theme file
+type styleJSON struct {
+	Fg        *Color `json:"fg,omitempty"`
+	Bg        *Color `json:"bg,omitempty"`
+	Bold      bool   `json:"bold,omitempty"`
+	Faint     bool   `json:"faint,omitempty"`
+	Italic    bool   `json:"italic,omitempty"`
+	Underline bool   `json:"underline,omitempty"`
+	Blink     bool   `json:"blink,omitempty"`
+	Reverse   bool   `json:"reverse,omitempty"`
+	Hidden    bool   `json:"hidden,omitempty"`
+	Strike    bool   `json:"strike,omitempty"`
+}
+
+func (j *styleJSON) attrs() []*bool {
+	return []*bool{&j.Bold, &j.Faint, &j.Italic, &j.Underline, &j.Blink, &j.Reverse, &j.Hidden, &j.Strike}
+}
+
+// MarshalJSON style as in a theme file
+func (s Style) MarshalJSON() ([]byte, error) {
+	var j styleJSON
+	if s.Fg != ColorDefault {
+		j.Fg = &s.Fg
+	}
+	if s.Bg != ColorDefault {
+		j.Bg = &s.Bg
+	}
+	for i, a := range j.attrs() {
+		*a = s.Attr&(1<<i) != 0
+	}
+	return json.Marshal(j)
+}
+
+// UnmarshalJSON style from a theme file
+func (s *Style) UnmarshalJSON(b []byte) error {
+	var j styleJSON
+	if err := json.Unmarshal(b, &j); err != nil {
+		return err
+	}
+
+	*s = StyleDefault
+	if j.Fg != nil {
+		s.Fg = *j.Fg
+	}
+	if j.Bg != nil {
+		s.Bg = *j.Bg
+	}
+	for i, a := range j.attrs() {
+		if *a {
+			s.Attr |= 1 << i
+		}
+	}
+	return nil
+}
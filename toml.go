@@ -0,0 +1,149 @@
+package game
+
+import (
+	"fmt"
+	"strings"
+)
+
+// tomlEntry a `key = value` line of a TOML file, a string value is a list of one
+type tomlEntry struct {
+	key    string
+	values []string
+	line   int
+}
+
+// parseTOML parse the subset of TOML the config files need: comments, and top level keys
+// with a string or a one-line array of strings. Tables and other values are errors.
+func parseTOML(data string) ([]tomlEntry, error) {
+	var entries []tomlEntry
+	seen := make(map[string]bool)
+	for i, l := range strings.Split(data, "\n") {
+		p := &tomlParser{s: strings.TrimSpace(strings.TrimSuffix(l, "\r"))}
+		if p.end() {
+			continue
+		}
+
+		e, err := p.entry()
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %v", i+1, err)
+		}
+		if seen[e.key] {
+			return nil, fmt.Errorf("line %d: duplicate key %q", i+1, e.key)
+		}
+		seen[e.key] = true
+		e.line = i + 1
+		entries = append(entries, e)
+	}
+	return entries, nil
+}
+
+// tomlParser the rest of a line to parse
+type tomlParser struct {
+	s string
+}
+
+// end skip the blanks, and whether the line ends or a comment starts
+func (p *tomlParser) end() bool {
+	p.s = strings.TrimLeft(p.s, " \t")
+	return p.s == "" || p.s[0] == '#'
+}
+
+// next skip the blanks and take byte `c` if it is the next
+func (p *tomlParser) next(c byte) bool {
+	p.end()
+	if p.s != "" && p.s[0] == c {
+		p.s = p.s[1:]
+		return true
+	}
+	return false
+}
+
+func (p *tomlParser) entry() (e tomlEntry, err error) {
+	if p.s[0] == '[' {
+		return e, fmt.Errorf("tables are not supported")
+	}
+	if e.key, err = p.key(); err != nil {
+		return
+	}
+	if !p.next('=') {
+		return e, fmt.Errorf("missing = after %q", e.key)
+	}
+
+	if p.next('[') {
+		e.values = []string{}
+		for !p.next(']') {
+			v, err := p.str()
+			if err != nil {
+				return e, err
+			}
+			e.values = append(e.values, v)
+			if !p.next(',') && !strings.HasPrefix(p.s, "]") {
+				return e, fmt.Errorf("missing , or ] in the array of %q", e.key)
+			}
+		}
+	} else {
+		v, err := p.str()
+		if err != nil {
+			return e, err
+		}
+		e.values = []string{v}
+	}
+
+	if !p.end() {
+		return e, fmt.Errorf("unexpected %q after the value of %q", p.s, e.key)
+	}
+	return
+}
+
+// key a bare or quoted key
+func (p *tomlParser) key() (string, error) {
+	if p.s[0] == '"' || p.s[0] == '\'' {
+		return p.str()
+	}
+
+	n := 0
+	for n < len(p.s) && (p.s[n] >= 'a' && p.s[n] <= 'z' || p.s[n] >= 'A' && p.s[n] <= 'Z' ||
+		p.s[n] >= '0' && p.s[n] <= '9' || p.s[n] == '_' || p.s[n] == '-') {
+		n++
+	}
+	if n == 0 {
+		return "", fmt.Errorf("bad key at %q", p.s)
+	}
+	key := p.s[:n]
+	p.s = p.s[n:]
+	return key, nil
+}
+
+// str a basic string "..." with escapes, or a literal string '...'
+func (p *tomlParser) str() (string, error) {
+	p.end()
+	if p.s == "" || p.s[0] != '"' && p.s[0] != '\'' {
+		return "", fmt.Errorf("want a string at %q", p.s)
+	}
+
+	quote := p.s[0]
+	var b strings.Builder
+	for i := 1; i < len(p.s); i++ {
+		c := p.s[i]
+		switch {
+		case c == quote:
+			p.s = p.s[i+1:]
+			return b.String(), nil
+		case c == '\\' && quote == '"' && i+1 < len(p.s):
+			i++
+			switch p.s[i] {
+			case '"', '\\':
+				b.WriteByte(p.s[i])
+			case 't':
+				b.WriteByte('\t')
+			case 'n':
+				b.WriteByte('\n')
+			default:
+				return "", fmt.Errorf("unsupported escape \\%c", p.s[i])
+			}
+		default:
+			b.WriteByte(c)
+		}
+	}
+	return "", fmt.Errorf("unterminated string %s", p.s)
+}
@@ -0,0 +1,312 @@
+package game
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Widget a part of a frame placed by a layout
+type Widget interface {
+	// Size the rows and cols the widget needs
+	Size() (rows, cols int)
+	// Draw draw the widget in the area of `rows` x `cols` from Point `p`
+	Draw(s *Screen, p Point, rows, cols int)
+}
+
+// DrawWidget draw `w` from Point `p` in the size it needs
+func DrawWidget(s *Screen, p Point, w Widget) {
+	rows, cols := w.Size()
+	w.Draw(s, p, rows, cols)
+}
+
+// DrawCentered draw `w` in the middle of the screen, from the origin
+func DrawCentered(s *Screen, w Widget) {
+	rows, cols := w.Size()
+	s.SetOrigin(s.Center(rows, cols))
+	w.Draw(s, Point{}, rows, cols)
+}
+
+// Canvas a widget drawn by a function with its left-top as (0, 0), e.g. a game board
+type Canvas struct {
+	Rows, Cols int
+	Paint      func(s *Screen)
+}
+
+// Size rows and cols of the canvas
+func (c Canvas) Size() (rows, cols int) {
+	return c.Rows, c.Cols
+}
+
+// Draw paint the canvas from Point `p`
+func (c Canvas) Draw(s *Screen, p Point, _, _ int) {
+	origin := s.Origin()
+	s.SetOrigin(origin.Add(p))
+	c.Paint(s)
+	s.SetOrigin(origin)
+}
+
+// Label a line of text, cut to the width of its area
+type Label struct {
+	Text  string
+	Style Style
+	Width int // the cols it needs, 0 for the width of Text
+}
+
+// Size one row, Width or the width of Text
+func (l Label) Size() (rows, cols int) {
+	if l.Width > 0 {
+		return 1, l.Width
+	}
+	return 1, StringWidth(l.Text)
+}
+
+// Draw draw the text from Point `p`
+func (l Label) Draw(s *Screen, p Point, _, cols int) {
+	s.DrawString(p, Truncate(l.Text, cols, ""), l.Style)
+}
+
+// Spacer blank space
+type Spacer struct {
+	Rows, Cols int
+}
+
+// Size rows and cols of the space
+func (sp Spacer) Size() (rows, cols int) {
+	return sp.Rows, sp.Cols
+}
+
+// Draw nothing
+func (Spacer) Draw(*Screen, Point, int, int) {}
+
+// Border the runes of a box border
+type Border struct {
+	TopLeft, Top, TopRight          rune
+	Left, Right                     rune
+	BottomLeft, Bottom, BottomRight rune
+}
+
+var (
+	BorderASCII   = Border{'+', '-', '+', '|', '|', '+', '-', '+'}
+	BorderSingle  = Border{'┌', '─', '┐', '│', '│', '└', '─', '┘'}
+	BorderRounded = Border{'╭', '─', '╮', '│', '│', '╰', '─', '╯'}
+	BorderDouble  = Border{'╔', '═', '╗', '║', '║', '╚', '═', '╝'}
+	BorderHeavy   = Border{'┏', '━', '┓', '┃', '┃', '┗', '━', '┛'}
+)
+
+// Box a border around a widget, with the title on the top border
+type Box struct {
+	Title   string
+	Border  Border // BorderSingle if zero
+	Style   Style  // style of the border
+	Padding int    // blank cols between the border and the child on both sides
+	Child   Widget
+}
+
+// Size the child with the border and padding, wide enough for the title
+func (b Box) Size() (rows, cols int) {
+	if b.Child != nil {
+		rows, cols = b.Child.Size()
+	}
+	cols += 2 * b.Padding
+	if w := StringWidth(b.Title) + 4; b.Title != "" && cols < w {
+		cols = w
+	}
+	return rows + 2, cols + 2
+}
+
+// Draw draw the border in the whole area and the child inside
+func (b Box) Draw(s *Screen, p Point, rows, cols int) {
+	if rows < 2 || cols < 2 {
+		return
+	}
+
+	br := b.Border
+	if br == (Border{}) {
+		br = BorderSingle
+	}
+
+	s.Set(p, br.TopLeft, b.Style)
+	s.Fill(p.Add(Point{Y: 1}), cols-2, br.Top, b.Style)
+	s.Set(p.Add(Point{Y: cols - 1}), br.TopRight, b.Style)
+	for i := 1; i < rows-1; i++ {
+		s.Set(p.Add(Point{X: i}), br.Left, b.Style)
+		s.Set(p.Add(Point{X: i, Y: cols - 1}), br.Right, b.Style)
+	}
+	s.Set(p.Add(Point{X: rows - 1}), br.BottomLeft, b.Style)
+	s.Fill(p.Add(Point{X: rows - 1, Y: 1}), cols-2, br.Bottom, b.Style)
+	s.Set(p.Add(Point{X: rows - 1, Y: cols - 1}), br.BottomRight, b.Style)
+
+	if b.Title != "" && cols > 4 {
+		s.DrawString(p.Add(Point{Y: 2}), Truncate(b.Title, cols-4, ""), b.Style.Bold())
+	}
+	if b.Child != nil {
+		b.Child.Draw(s, p.Add(Point{X: 1, Y: 1 + b.Padding}), rows-2, cols-2-2*b.Padding)
+	}
+}
+
+// VBox widgets from top to bottom, each as wide as the box
+type VBox struct {
+	Gap      int // blank rows between the widgets
+	Children []Widget
+}
+
+// Size the sum of the rows, the widest cols
+func (v VBox) Size() (rows, cols int) {
+	for i, c := range v.Children {
+		r, w := c.Size()
+		if rows += r; i > 0 {
+			rows += v.Gap
+		}
+		if w > cols {
+			cols = w
+		}
+	}
+	return
+}
+
+// Draw place the widgets down from Point `p`, the last ones are left out when there is no room
+func (v VBox) Draw(s *Screen, p Point, rows, cols int) {
+	end := p.X + rows
+	for _, c := range v.Children {
+		r, _ := c.Size()
+		if p.X+r > end {
+			return
+		}
+		c.Draw(s, p, r, cols)
+		p.X += r + v.Gap
+	}
+}
+
+// HBox widgets from left to right, aligned at the top
+type HBox struct {
+	Gap      int // blank cols between the widgets
+	Children []Widget
+}
+
+// Size the sum of the cols, the highest rows
+func (h HBox) Size() (rows, cols int) {
+	for i, c := range h.Children {
+		r, w := c.Size()
+		if cols += w; i > 0 {
+			cols += h.Gap
+		}
+		if r > rows {
+			rows = r
+		}
+	}
+	return
+}
+
+// Draw place the widgets right from Point `p`, the last ones are left out when there is no room
+func (h HBox) Draw(s *Screen, p Point, rows, cols int) {
+	end := p.Y + cols
+	for _, c := range h.Children {
+		r, w := c.Size()
+		if p.Y+w > end {
+			return
+		}
+		if r > rows {
+			r = rows
+		}
+		c.Draw(s, p, r, w)
+		p.Y += w + h.Gap
+	}
+}
+
+// KeyHelp the lines `keys -> help` of the bindings, the keys aligned, the unbound ones left out
+type KeyHelp struct {
+	Bindings []Binding
+	Style    Style
+}
+
+// lines `keys -> help` of the bindings
+func (k KeyHelp) lines() []string {
+	keys := make([]string, len(k.Bindings))
+	width := 0
+	for i, b := range k.Bindings {
+		if len(b.Keys) == 0 { // unbound
+			continue
+		}
+		names := make([]string, len(b.Keys))
+		for j, key := range b.Keys {
+			names[j] = key.String()
+		}
+		if keys[i] = strings.Join(names, "/"); StringWidth(keys[i]) > width {
+			width = StringWidth(keys[i])
+		}
+	}
+
+	lines := make([]string, 0, len(k.Bindings))
+	for i, b := range k.Bindings {
+		if keys[i] != "" {
+			lines = append(lines, PadRight(keys[i], width)+" -> "+b.Help)
+		}
+	}
+	return lines
+}
+
+// Size a row for each binding
+func (k KeyHelp) Size() (rows, cols int) {
+	return textSize(k.lines())
+}
+
+// Draw draw the lines from Point `p`
+func (k KeyHelp) Draw(s *Screen, p Point, rows, cols int) {
+	drawText(s, p, rows, cols, k.lines(), k.Style)
+}
+
+// Stat a named value of a ScoreBoard
+type Stat struct {
+	Name  string
+	Value interface{}
+}
+
+// ScoreBoard the lines `name: value` of the stats, the values aligned
+type ScoreBoard struct {
+	Stats []Stat
+	Style Style
+}
+
+func (b ScoreBoard) lines() []string {
+	width := 0
+	for _, st := range b.Stats {
+		if w := StringWidth(st.Name); w > width {
+			width = w
+		}
+	}
+
+	lines := make([]string, len(b.Stats))
+	for i, st := range b.Stats {
+		lines[i] = PadRight(st.Name+":", width+1) + " " + fmt.Sprint(st.Value)
+	}
+	return lines
+}
+
+// Size a row for each stat
+func (b ScoreBoard) Size() (rows, cols int) {
+	return textSize(b.lines())
+}
+
+// Draw draw the lines from Point `p`
+func (b ScoreBoard) Draw(s *Screen, p Point, rows, cols int) {
+	drawText(s, p, rows, cols, b.lines(), b.Style)
+}
+
+func textSize(lines []string) (rows, cols int) {
+	for _, l := range lines {
+		if w := StringWidth(l); w > cols {
+			cols = w
+		}
+	}
+	return len(lines), cols
+}
+
+// drawText draw `lines` in the area, cut to fit
+func drawText(s *Screen, p Point, rows, cols int, lines []string, st Style) {
+	for i, l := range lines {
+		if i >= rows {
+			return
+		}
+		s.DrawString(p.Add(Point{X: i}), Truncate(l, cols, ""), st)
+	}
+}
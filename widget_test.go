@@ -0,0 +1,80 @@
+package game
+
+import (
+	"strings"
+	"testing"
+)
+
+// text the runes of row `x`, right-trimmed
+func text(s *Screen, x int) string {
+	_, cols := s.Size()
+	var b strings.Builder
+	for y := 0; y < cols; y++ {
+		if c := s.Get(Point{X: x, Y: y}); c.Rune != 0 {
+			b.WriteRune(c.Rune)
+		}
+	}
+	return strings.TrimRight(b.String(), " ")
+}
+
+func TestWidgetLayout(t *testing.T) {
+	w := HBox{Gap: 1, Children: []Widget{
+		Canvas{Rows: 2, Cols: 3, Paint: func(s *Screen) {
+			s.DrawString(Point{}, "###", StyleDefault)
+			s.DrawString(Point{X: 1}, "#.#", StyleDefault)
+		}},
+		VBox{Children: []Widget{
+			ScoreBoard{Stats: []Stat{{Name: "Score", Value: 12}, {Name: "Lines", Value: 3}}},
+			Box{Title: "Tips", Border: BorderASCII, Padding: 1, Child: KeyHelp{Bindings: []Binding{
+				{Keys: []Key{KeyRune('q')}, Help: "exit"},
+				{Keys: []Key{KeyRune('a'), KeyCode(SysLeft)}, Help: "left"},
+			}}},
+			Label{Text: "a long message", Width: 8},
+		}},
+	}}
+
+	if rows, cols := w.Size(); rows != 7 || cols != 22 {
+		t.Fatalf("size = %dx%d, want 7x22", rows, cols)
+	}
+
+	s := NewScreen(7, 22)
+	DrawWidget(s, Point{}, w)
+	want := []string{
+		"### Score: 12",
+		"#.# Lines: 3",
+		"    +-Tips-----------+",
+		"    | q      -> exit |",
+		"    | a/Left -> left |",
+		"    +----------------+",
+		"    a long message", // as wide as the box above
+	}
+	for i, l := range want {
+		if got := text(s, i); got != l {
+			t.Errorf("line %d = %q, want %q", i, got, l)
+		}
+	}
+	if c := s.Get(Point{X: 2, Y: 6}); c.Style.Attr&AttrBold == 0 {
+		t.Errorf("title cell = %+v, want bold", c)
+	}
+}
+
+func TestWidgetClip(t *testing.T) {
+	w := VBox{Children: []Widget{Label{Text: "one"}, Label{Text: "two"}}}
+
+	s := NewScreen(1, 10)
+	w.Draw(s, Point{}, 1, 2)
+	if got := text(s, 0); got != "on" {
+		t.Errorf("clipped line = %q, want %q", got, "on")
+	}
+
+	s = NewScreen(4, 10)
+	DrawCentered(s, Box{Border: BorderSingle, Child: Label{Text: "hi"}})
+	if o := s.Origin(); o != (Point{X: 0, Y: 3}) {
+		t.Errorf("origin = %v, want (0, 3)", o)
+	}
+	for i, l := range []string{"┌──┐", "│hi│", "└──┘"} {
+		if got := text(s, i); got != l {
+			t.Errorf("line %d = %q, want %q", i, got, l)
+		}
+	}
+}
@@ -0,0 +1,68 @@
+package game
+
+import "testing"
+
+func TestStringWidth(t *testing.T) {
+	tests := []struct {
+		s    string
+		want int
+	}{
+		{"", 0},
+		{"Score: 10", 9},
+		{"俄罗斯方块", 10},
+		{"ｑ退出", 6},
+		{"é", 1},    // e + combining acute
+		{"┌─┐", 3},   // box drawing, ambiguous
+		{"🐍", 2},     // emoji
+		{"☺️", 2},    // text symbol in emoji presentation
+		{"👨‍👩‍👧", 2}, // family, ZWJ sequence
+		{"🇨🇳", 2},    // flag, regional indicator pair
+		{"a\tb", 2},  // controls take no column
+		{"한글", 4},
+	}
+
+	for _, tt := range tests {
+		if got := StringWidth(tt.s); got != tt.want {
+			t.Errorf("StringWidth(%q) = %d, want %d", tt.s, got, tt.want)
+		}
+	}
+}
+
+func TestAmbiguousWidth(t *testing.T) {
+	defer func(w int) { AmbiguousWidth = w }(AmbiguousWidth)
+
+	AmbiguousWidth = 2
+	if got := StringWidth("→°"); got != 4 {
+		t.Errorf("ambiguous width = %d, want 4", got)
+	}
+}
+
+func TestTruncate(t *testing.T) {
+	tests := []struct {
+		s     string
+		width int
+		tail  string
+		want  string
+	}{
+		{"hello", 10, "…", "hello"},
+		{"hello world", 8, "…", "hello w…"},
+		{"2022-09-21 03:04:05 PM", 20, "", "2022-09-21 03:04:05 "},
+		{"游戏结束！", 5, "", "游戏"},
+		{"游戏结束！", 6, "…", "游戏…"},
+		{"ééé", 2, "", "éé"},
+		{"abc", 0, "…", ""},
+	}
+
+	for _, tt := range tests {
+		if got := Truncate(tt.s, tt.width, tt.tail); got != tt.want {
+			t.Errorf("Truncate(%q, %d) = %q, want %q", tt.s, tt.width, got, tt.want)
+		}
+	}
+
+	if got := PadRight("分数", 6) + "|"; got != "分数  |" {
+		t.Errorf("PadRight = %q", got)
+	}
+	if got := PadLeft("分数", 5); got != " 分数" {
+		t.Errorf("PadLeft = %q", got)
+	}
+}